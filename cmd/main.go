@@ -2,13 +2,18 @@ package main
 
 import (
 	"io"
+	"log"
 	"os"
 	"time"
 
 	"github.com/ASaifaji/as-gin-ecommerce/config"
 	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/jobs"
+	"github.com/ASaifaji/as-gin-ecommerce/mailer"
 	"github.com/ASaifaji/as-gin-ecommerce/middlewares"
+	"github.com/ASaifaji/as-gin-ecommerce/notifications"
 	"github.com/ASaifaji/as-gin-ecommerce/routes"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-contrib/cors"
 )
@@ -21,7 +26,29 @@ func setupLogOutput() {
 func main () {
 	// Setup conf and db
 	config.LoadConfig()
+	if err := config.CheckSecrets(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
+	if err := utils.LoadJWTKeys(utils.JWTKeyOptions{
+		Algorithm:        config.JWTConfig.Algorithm,
+		KeyID:            config.JWTConfig.KeyID,
+		Secret:           config.JWTConfig.Secret,
+		PrivateKeyFile:   config.JWTConfig.PrivateKeyFile,
+		VerificationKeys: config.JWTConfig.VerificationKeys,
+	}); err != nil {
+		log.Fatal("Failed to load JWT keys: ", err)
+	}
+	utils.AccessTokenTTL = config.JWTConfig.AccessTokenTTL
 	database.ConnectDB()
+	notifications.Setup(config.AppConfig.Notifier, config.AppConfig.NotificationFile)
+	mailer.Setup(config.MailConfig.Mailer, mailer.SMTPMailer{
+		Host:     config.MailConfig.SMTPHost,
+		Port:     config.MailConfig.SMTPPort,
+		Username: config.MailConfig.SMTPUser,
+		Password: config.MailConfig.SMTPPass,
+		From:     config.MailConfig.From,
+	})
+	jobs.Start()
 
 	setupLogOutput()
 	
@@ -36,8 +63,9 @@ func main () {
 		cors.New(cors.Config{
 			AllowOrigins:		[]string{"http://localhost:5173"},
 			AllowMethods:		[]string{"GET", "POST", "POST", "DELETE", "OPTIONS"},
-			AllowHeaders:		[]string{"Origin", "Content-Type", "Accept", "Authorization"},
-			ExposeHeaders:		[]string{"Content-Length"},
+			AllowHeaders:		[]string{"Origin", "Content-Type", "Accept", "Authorization", middlewares.IdempotencyKeyHeader},
+			ExposeHeaders:		[]string{"Content-Length", "Idempotent-Replayed", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy"},
 			AllowCredentials:	true,
 			MaxAge:				12*time.Hour,
 		}),
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+)
+
+// Hapus permanen data yang sudah lama di trash.
+// Contoh: go run ./cmd/purge -retention 720h
+func main() {
+	config.LoadConfig()
+
+	retention := flag.Duration("retention", config.JobConfig.PurgeRetention, "how long soft-deleted rows are kept before permanent removal")
+	flag.Parse()
+
+	database.ConnectDB()
+
+	purged, err := database.PurgeSoftDeleted(*retention)
+	if err != nil {
+		log.Fatal("Purge failed:", err)
+	}
+
+	fmt.Printf("Purged %d products, %d categories, %d users deleted more than %s ago\n",
+		purged["products"], purged["categories"], purged["users"], *retention)
+}
@@ -1,21 +1,29 @@
 package config
 
 import (
+    "fmt"
     "log"
     "os"
+    "strconv"
+    "time"
 
+    "github.com/ASaifaji/as-gin-ecommerce/utils"
     "github.com/joho/godotenv"
     "golang.org/x/oauth2"
     "golang.org/x/oauth2/google"
 )
 
 type appConfig struct {
-    AppPort     string
-    DBUser      string
-    DBPass      string
-    DBHost      string
-    DBPort      string
-    DBName      string
+    AppPort          string
+    DBUser           string
+    DBPass           string
+    DBHost           string
+    DBPort           string
+    DBName           string
+    CartSecret       string // untuk menandatangani cookie guest cart
+    Notifier         string // "log" atau "file"
+    NotificationFile string // tujuan notifikasi jika Notifier = "file"
+    AppURL           string // URL frontend untuk link di email
 }
 
 type adminConfig struct{
@@ -24,8 +32,78 @@ type adminConfig struct{
     AdminPass   string
 }
 
+// interval untuk background job
+type jobConfig struct {
+    RelatedProductsInterval       time.Duration
+    PurgeRetention                time.Duration // umur minimum data di trash sebelum dihapus permanen
+    AbandonedCartInterval         time.Duration
+    AbandonedCartAfter            time.Duration // cart dianggap abandoned jika item tidak disentuh selama ini
+    AbandonedCartConversionWindow time.Duration // order dalam jangka ini dihitung sebagai konversi
+    IdempotencyKeyTTL             time.Duration // response untuk Idempotency-Key disimpan selama ini
+    IdempotencyCleanupInterval    time.Duration
+    TokenCleanupInterval          time.Duration // hapus token dicabut / refresh token yang sudah kedaluwarsa
+}
+
+// key untuk tanda tangan JWT, lihat utils.JWTKeyOptions
+type jwtConfig struct {
+    Algorithm        string
+    KeyID            string
+    Secret           string
+    PrivateKeyFile   string
+    VerificationKeys string // key lama yang masih diterima saat rotasi
+    AccessTokenTTL   time.Duration
+    RefreshTokenTTL  time.Duration
+}
+
+// pengiriman email transaksional
+type mailConfig struct {
+    Mailer   string // "smtp" atau "log" (development)
+    SMTPHost string
+    SMTPPort string
+    SMTPUser string
+    SMTPPass string
+    From     string
+}
+
+// batas request untuk endpoint yang rawan disalahgunakan, lihat middlewares.RateLimit
+type rateLimitConfig struct {
+    Enabled      bool
+    AuthLimit    int // login, register, dll. per IP per route
+    AuthWindow   time.Duration
+    SearchLimit  int // listing / pencarian produk per IP
+    SearchWindow time.Duration
+}
+
+// pengaturan alur autentikasi
+type authConfig struct {
+    PasswordResetTTL                time.Duration
+    EmailVerificationSecret         string
+    EmailVerificationTTL            time.Duration
+    RequireVerifiedEmailForCheckout bool
+    TOTPIssuer                      string // nama yang tampil di aplikasi authenticator
+    TwoFactorChallengeTTL           time.Duration
+    Require2FAForAdmins             bool
+    LoginAttemptWindow              time.Duration // penghitung login gagal direset setelah jeda ini
+    LoginBackoffAfter               int           // login gagal per akun sebelum backoff berlaku
+    LoginIPBackoffAfter             int           // login gagal per IP sebelum backoff berlaku
+    LoginBackoffBase                time.Duration // jeda awal, dikali dua setiap kegagalan berikutnya
+    LoginBackoffMax                 time.Duration
+    LoginLockoutThreshold           int // login gagal berturut-turut sebelum akun dikunci
+    LoginLockoutDuration            time.Duration
+}
+
 var AdminConfig *adminConfig
 
+var MailConfig *mailConfig
+
+var AuthConfig *authConfig
+
+var RateLimitConfig *rateLimitConfig
+
+var JWTConfig *jwtConfig
+
+var JobConfig *jobConfig
+
 var AppConfig *appConfig
 
 var GoogleOAuthConfig *oauth2.Config
@@ -38,12 +116,16 @@ func LoadConfig() {
     }
 
     AppConfig = &appConfig{
-        AppPort:    getEnv("APP_PORT", "8080"),
-        DBUser:     getEnv("DB_USER", "root"),
-        DBPass:     getEnv("DB_PASS", ""),
-        DBHost:     getEnv("DB_HOST", "127.0.0.1"),
-        DBPort:     getEnv("DB_PORT", "3306"),
-        DBName:     getEnv("DB_NAME", "mydb"),
+        AppPort:          getEnv("APP_PORT", "8080"),
+        DBUser:           getEnv("DB_USER", "root"),
+        DBPass:           getEnv("DB_PASS", ""),
+        DBHost:           getEnv("DB_HOST", "127.0.0.1"),
+        DBPort:           getEnv("DB_PORT", "3306"),
+        DBName:           getEnv("DB_NAME", "mydb"),
+        CartSecret:       getEnv("CART_SECRET", ""),
+        Notifier:         getEnv("NOTIFIER", "log"),
+        NotificationFile: getEnv("NOTIFICATION_FILE", "notifications.log"),
+        AppURL:           getEnv("APP_URL", "http://localhost:5173"),
     }
 
     AdminConfig = &adminConfig{
@@ -52,6 +134,61 @@ func LoadConfig() {
         AdminPass:  getEnv("ADMIN_PASS", "abcd1234"),
     }
 
+    JobConfig = &jobConfig{
+        RelatedProductsInterval:       getEnvDuration("RELATED_PRODUCTS_INTERVAL", 6*time.Hour),
+        PurgeRetention:                getEnvDuration("PURGE_RETENTION", 30*24*time.Hour),
+        AbandonedCartInterval:         getEnvDuration("ABANDONED_CART_INTERVAL", time.Hour),
+        AbandonedCartAfter:            getEnvDuration("ABANDONED_CART_AFTER", 24*time.Hour),
+        AbandonedCartConversionWindow: getEnvDuration("ABANDONED_CART_CONVERSION_WINDOW", 7*24*time.Hour),
+        IdempotencyKeyTTL:             getEnvDuration("IDEMPOTENCY_KEY_TTL", 24*time.Hour),
+        IdempotencyCleanupInterval:    getEnvDuration("IDEMPOTENCY_CLEANUP_INTERVAL", time.Hour),
+        TokenCleanupInterval:          getEnvDuration("TOKEN_CLEANUP_INTERVAL", time.Hour),
+    }
+
+    JWTConfig = &jwtConfig{
+        Algorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+        KeyID:            getEnv("JWT_KEY_ID", "default"),
+        Secret:           getEnv("JWT_SECRET", ""), // wajib diisi untuk HS256, dicek oleh utils.LoadJWTKeys
+        PrivateKeyFile:   getEnv("JWT_PRIVATE_KEY_FILE", ""),
+        VerificationKeys: getEnv("JWT_VERIFICATION_KEYS", ""),
+        AccessTokenTTL:   getEnvDuration("JWT_ACCESS_TOKEN_TTL", 15*time.Minute),
+        RefreshTokenTTL:  getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+    }
+
+    MailConfig = &mailConfig{
+        Mailer:   getEnv("MAILER", "smtp"),
+        SMTPHost: getEnv("SMTP_HOST", "localhost"),
+        SMTPPort: getEnv("SMTP_PORT", "587"),
+        SMTPUser: getEnv("SMTP_USER", ""),
+        SMTPPass: getEnv("SMTP_PASS", ""),
+        From:     getEnv("MAIL_FROM", "no-reply@example.com"),
+    }
+
+    AuthConfig = &authConfig{
+        PasswordResetTTL:                getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+        EmailVerificationSecret:         getEnv("EMAIL_VERIFICATION_SECRET", ""),
+        EmailVerificationTTL:            getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+        RequireVerifiedEmailForCheckout: getEnvBool("REQUIRE_VERIFIED_EMAIL_FOR_CHECKOUT", false),
+        TOTPIssuer:                      getEnv("TOTP_ISSUER", "AS Gin Ecommerce"),
+        TwoFactorChallengeTTL:           getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+        Require2FAForAdmins:             getEnvBool("REQUIRE_2FA_FOR_ADMINS", false),
+        LoginAttemptWindow:              getEnvDuration("LOGIN_ATTEMPT_WINDOW", time.Hour),
+        LoginBackoffAfter:               getEnvInt("LOGIN_BACKOFF_AFTER", 3),
+        LoginIPBackoffAfter:             getEnvInt("LOGIN_IP_BACKOFF_AFTER", 20),
+        LoginBackoffBase:                getEnvDuration("LOGIN_BACKOFF_BASE", time.Second),
+        LoginBackoffMax:                 getEnvDuration("LOGIN_BACKOFF_MAX", 15*time.Minute),
+        LoginLockoutThreshold:           getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 10),
+        LoginLockoutDuration:            getEnvDuration("LOGIN_LOCKOUT_DURATION", 30*time.Minute),
+    }
+
+    RateLimitConfig = &rateLimitConfig{
+        Enabled:      getEnvBool("RATE_LIMIT_ENABLED", true),
+        AuthLimit:    getEnvInt("RATE_LIMIT_AUTH_LIMIT", 10),
+        AuthWindow:   getEnvDuration("RATE_LIMIT_AUTH_WINDOW", time.Minute),
+        SearchLimit:  getEnvInt("RATE_LIMIT_SEARCH_LIMIT", 60),
+        SearchWindow: getEnvDuration("RATE_LIMIT_SEARCH_WINDOW", time.Minute),
+    }
+
     GoogleOAuthConfig = &oauth2.Config{
         RedirectURL:    "http://localhost:8080/api/auth/google/callback",
         ClientID:       getEnv("GoogleOAuthClientID", ""),
@@ -66,4 +203,61 @@ func getEnv(key, fallback string) string {
         return value
     }
     return fallback
-}
\ No newline at end of file
+}
+
+// CheckSecrets memastikan secret untuk tanda tangan HMAC sudah diisi. Tanpa
+// secret sendiri, siapa pun bisa membuat token yang valid.
+func CheckSecrets() error {
+    secrets := map[string]string{
+        "CART_SECRET":               AppConfig.CartSecret,
+        "EMAIL_VERIFICATION_SECRET": AuthConfig.EmailVerificationSecret,
+    }
+    for key, value := range secrets {
+        if len(value) < utils.MinHMACSecretLength {
+            return fmt.Errorf("%s must be set to at least %d bytes", key, utils.MinHMACSecretLength)
+        }
+    }
+    return nil
+}
+
+// getEnvInt membaca bilangan bulat
+func getEnvInt(key string, fallback int) int {
+    value, exists := os.LookupEnv(key)
+    if !exists {
+        return fallback
+    }
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        log.Printf("Invalid integer for %s: %q, using %d\n", key, value, fallback)
+        return fallback
+    }
+    return n
+}
+
+// getEnvBool membaca "true"/"false"/"1"/"0"
+func getEnvBool(key string, fallback bool) bool {
+    value, exists := os.LookupEnv(key)
+    if !exists {
+        return fallback
+    }
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        log.Printf("Invalid boolean for %s: %q, using %t\n", key, value, fallback)
+        return fallback
+    }
+    return b
+}
+
+// getEnvDuration membaca durasi format Go (contoh: "30m", "6h")
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+    value, exists := os.LookupEnv(key)
+    if !exists {
+        return fallback
+    }
+    d, err := time.ParseDuration(value)
+    if err != nil {
+        log.Printf("Invalid duration for %s: %q, using %s\n", key, value, fallback)
+        return fallback
+    }
+    return d
+}
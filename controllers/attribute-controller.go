@@ -0,0 +1,406 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetAllAttributes(ctx *gin.Context) {
+	var attributes []models.Attribute
+	if err := database.DB.Order("name ASC").Find(&attributes).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attributes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"attributes": attributes,
+	})
+}
+
+func CreateAttribute(ctx *gin.Context) {
+	var input models.AttributeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attribute := models.Attribute{
+		Name:    input.Name,
+		Slug:    attributeSlug(input),
+		Type:    input.Type,
+		Unit:    input.Unit,
+		Options: normalizeAttributeOptions(input.Options),
+	}
+	if attribute.Type == models.AttributeEnum && attribute.Options == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Enum attributes require options"})
+		return
+	}
+
+	var existing models.Attribute
+	if err := database.DB.Where("slug = ?", attribute.Slug).First(&existing).Error; err == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Attribute already exists"})
+		return
+	}
+
+	if err := database.DB.Create(&attribute).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create attribute"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":   "Attribute created successfully",
+		"attribute": attribute,
+	})
+}
+
+func UpdateAttribute(ctx *gin.Context) {
+	attributeID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attribute ID format"})
+		return
+	}
+
+	var input models.AttributeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var attribute models.Attribute
+	if err := database.DB.First(&attribute, attributeID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	// Tipe tidak boleh diubah jika sudah ada nilai tersimpan
+	if input.Type != attribute.Type {
+		var used int64
+		database.DB.Model(&models.AttributeValue{}).Where("attribute_id = ?", attribute.ID).Count(&used)
+		if used > 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Cannot change type of an attribute that is already in use"})
+			return
+		}
+	}
+
+	updateMap := map[string]interface{}{
+		"name":    input.Name,
+		"slug":    attributeSlug(input),
+		"type":    input.Type,
+		"unit":    input.Unit,
+		"options": normalizeAttributeOptions(input.Options),
+	}
+	if err := database.DB.Model(&attribute).Updates(updateMap).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update attribute"})
+		return
+	}
+
+	database.DB.First(&attribute, attributeID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Attribute updated successfully",
+		"attribute": attribute,
+	})
+}
+
+func DeleteAttribute(ctx *gin.Context) {
+	var attribute models.Attribute
+	if err := database.DB.First(&attribute, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Attribute not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("attribute_id = ?", attribute.ID).Delete(&models.AttributeValue{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("attribute_id = ?", attribute.ID).Delete(&models.CategoryAttribute{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&attribute).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attribute"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Attribute deleted successfully",
+		"id":      attribute.ID,
+	})
+}
+
+// Attribute set category, termasuk yang diwarisi dari parent category
+func GetCategoryAttributes(ctx *gin.Context) {
+	categoryID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+
+	set, err := categoryAttributeSet(uint(categoryID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve category attributes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"category_id": categoryID,
+		"attributes":  set,
+	})
+}
+
+// Ganti attribute set milik category (tidak termasuk yang diwarisi)
+func SetCategoryAttributes(ctx *gin.Context) {
+	categoryID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID format"})
+		return
+	}
+
+	var input models.CategoryAttributeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var category models.Category
+	if err := database.DB.First(&category, categoryID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Category not found"})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("category_id = ?", category.ID).Delete(&models.CategoryAttribute{}).Error; err != nil {
+			return err
+		}
+		for _, a := range input.Attributes {
+			var attribute models.Attribute
+			if err := tx.First(&attribute, a.AttributeID).Error; err != nil {
+				return fmt.Errorf("attribute %d not found", a.AttributeID)
+			}
+			link := models.CategoryAttribute{CategoryID: category.ID, AttributeID: attribute.ID, Required: a.Required}
+			if err := tx.Omit("Category", "Attribute").Create(&link).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, _ := categoryAttributeSet(category.ID)
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Category attributes updated successfully",
+		"attributes": set,
+	})
+}
+
+// Set nilai attribute produk. Hanya attribute dari attribute set category
+// produk yang boleh diisi; nilai kosong menghapus attribute tersebut.
+func SetProductAttributes(ctx *gin.Context) {
+	productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID format"})
+		return
+	}
+
+	var input models.ProductAttributesInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := database.DB.First(&product, productID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	set, err := categoryAttributeSet(product.CategoryID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load category attributes"})
+		return
+	}
+	allowed := make(map[string]models.Attribute, len(set))
+	for _, ca := range set {
+		allowed[ca.Attribute.Slug] = ca.Attribute
+	}
+
+	errs := make(map[string]string)
+	values := make(map[uint]models.AttributeValue)
+	var removed []uint
+	for slug, raw := range input.Values {
+		attribute, ok := allowed[slug]
+		if !ok {
+			errs[slug] = "attribute is not part of this product's category"
+			continue
+		}
+		if strings.TrimSpace(raw) == "" {
+			removed = append(removed, attribute.ID)
+			continue
+		}
+		value, number, err := normalizeAttributeValue(attribute, raw)
+		if err != nil {
+			errs[slug] = err.Error()
+			continue
+		}
+		values[attribute.ID] = models.AttributeValue{
+			ProductID:   product.ID,
+			AttributeID: attribute.ID,
+			Value:       value,
+			NumberValue: number,
+		}
+	}
+	if len(errs) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attribute values", "errors": errs})
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(removed) > 0 {
+			if err := tx.Where("product_id = ? AND attribute_id IN ?", product.ID, removed).
+				Delete(&models.AttributeValue{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, v := range values {
+			var existing models.AttributeValue
+			err := tx.Where("product_id = ? AND attribute_id = ?", v.ProductID, v.AttributeID).First(&existing).Error
+			if err == nil {
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"value":        v.Value,
+					"number_value": v.NumberValue,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Omit("Attribute").Create(&v).Error; err != nil {
+				return err
+			}
+		}
+
+		// Attribute wajib harus terisi
+		for _, ca := range set {
+			if !ca.Required {
+				continue
+			}
+			var count int64
+			tx.Model(&models.AttributeValue{}).
+				Where("product_id = ? AND attribute_id = ?", product.ID, ca.AttributeID).
+				Count(&count)
+			if count == 0 {
+				return fmt.Errorf("attribute %q is required", ca.Attribute.Slug)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var attributes []models.AttributeValue
+	database.DB.Preload("Attribute").Where("product_id = ?", product.ID).Find(&attributes)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":    "Product attributes updated successfully",
+		"attributes": attributes,
+	})
+}
+
+// Attribute set dari category beserta semua ancestor-nya
+func categoryAttributeSet(categoryID uint) ([]models.CategoryAttribute, error) {
+	index, err := loadCategoryIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint
+	for _, b := range categoryBreadcrumbs(index, categoryID) {
+		ids = append(ids, b.ID)
+	}
+
+	var set []models.CategoryAttribute
+	if len(ids) == 0 {
+		return []models.CategoryAttribute{}, nil
+	}
+	if err := database.DB.Preload("Attribute").Where("category_id IN ?", ids).Find(&set).Error; err != nil {
+		return nil, err
+	}
+
+	// Satu attribute bisa ada di beberapa level, gabungkan (required jika salah satu required)
+	merged := make([]models.CategoryAttribute, 0, len(set))
+	position := make(map[uint]int)
+	for _, ca := range set {
+		if i, ok := position[ca.AttributeID]; ok {
+			merged[i].Required = merged[i].Required || ca.Required
+			continue
+		}
+		position[ca.AttributeID] = len(merged)
+		merged = append(merged, ca)
+	}
+	return merged, nil
+}
+
+func normalizeAttributeValue(attribute models.Attribute, raw string) (string, *float64, error) {
+	value := strings.TrimSpace(raw)
+
+	switch attribute.Type {
+	case models.AttributeNumber:
+		numeric := value
+		if attribute.Unit != "" && strings.HasSuffix(strings.ToLower(numeric), strings.ToLower(attribute.Unit)) {
+			numeric = strings.TrimSpace(numeric[:len(numeric)-len(attribute.Unit)])
+		}
+		n, err := strconv.ParseFloat(numeric, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("must be a number")
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64) + attribute.Unit, &n, nil
+	case models.AttributeBoolean:
+		b, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			return "", nil, fmt.Errorf("must be true or false")
+		}
+		return strconv.FormatBool(b), nil, nil
+	case models.AttributeEnum:
+		for _, option := range strings.Split(attribute.Options, ",") {
+			if strings.EqualFold(option, value) {
+				return option, nil, nil
+			}
+		}
+		return "", nil, fmt.Errorf("must be one of: %s", attribute.Options)
+	}
+
+	if len(value) > 255 {
+		return "", nil, fmt.Errorf("must be at most 255 characters")
+	}
+	return value, nil, nil
+}
+
+func attributeSlug(input models.AttributeInput) string {
+	if input.Slug != "" {
+		return utils.Slugify(input.Slug)
+	}
+	return utils.Slugify(input.Name)
+}
+
+func normalizeAttributeOptions(options string) string {
+	var cleaned []string
+	for _, o := range strings.Split(options, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			cleaned = append(cleaned, o)
+		}
+	}
+	return strings.Join(cleaned, ",")
+}
@@ -60,7 +60,7 @@ func GoogleCallback(ctx *gin.Context) {
 				Email:    email,
 				Password: "", // Google users don’t need local password
 				Provider: "google",
-				Admin:	  false,
+				EmailVerifiedAt: utils.TimePtr(time.Now()), // email sudah diverifikasi Google
 				CreatedAt: time.Now(),
 				UpdatedAt: time.Now(),
 			}
@@ -78,10 +78,31 @@ func GoogleCallback(ctx *gin.Context) {
         
     }
 
-    // Generate JWT with your function signature
-	jwt, _ := utils.GenerateJWT(user.ID, user.Email, user.Admin)
+	// Akun lokal yang login lewat Google: email-nya sudah diverifikasi Google
+	if user.EmailVerifiedAt == nil {
+		database.DB.Model(&user).Update("email_verified_at", time.Now())
+	}
 
-	ctx.SetCookie("auth_token", jwt, 3600, "/", "", false, true)
+	// Akun dengan 2FA diarahkan ke halaman input kode. Challenge disimpan di
+	// cookie, bukan query string, supaya tidak tercatat di log / Referer.
+	if user.TwoFactorEnabledAt != nil {
+		challenge, err := utils.GenerateChallengeJWT(user.ID, config.AuthConfig.TwoFactorChallengeTTL)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+			return
+		}
+		ctx.SetCookie(utils.TwoFactorChallengeCookie, challenge, int(config.AuthConfig.TwoFactorChallengeTTL.Seconds()), utils.RefreshTokenCookiePath, "", false, true)
+		ctx.Redirect(http.StatusFound, "/login/2fa")
+		return
+	}
+
+    mergeGuestCart(ctx, user.ID)
+
+	// Access token + refresh token disimpan di cookie
+	if _, err := issueAuthTokens(ctx, user, "Google", false); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create session"})
+		return
+	}
 
 	ctx.Redirect(http.StatusFound, "/loggedin")
 
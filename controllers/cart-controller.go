@@ -1,12 +1,15 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/ASaifaji/as-gin-ecommerce/database"
 	"github.com/ASaifaji/as-gin-ecommerce/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func AddProductToCart(ctx *gin.Context) {
@@ -16,53 +19,22 @@ func AddProductToCart(ctx *gin.Context) {
 		return
 	}
 
-	// Get user ID from JWT claims set in middleware
-	userIDValue, exists := ctx.Get("id")
-	if !exists {
+	// User dari JWT claims, atau guest token dari cookie
+	owner, err := cartOwnerFromContext(ctx)
+	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	userID := userIDValue.(uint)
-
-	// Find or create cart for this user
-	var cart models.Cart
-	if err := database.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
-		// Create new cart if not found
-		cart = models.Cart{UserID: userID}
-		if err := database.DB.Create(&cart).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cart"})
-			return
-		}
-	}
 
-	// Check if product exists
-	var product models.Product
-	if err := database.DB.First(&product, input.ProductID).Error; err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	cart, err := findOrCreateCart(database.DB, owner)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cart"})
 		return
 	}
 
-	// Check if product already in cart
-	var existingItem models.CartItem
-	err := database.DB.Where("cart_id = ? AND product_id = ?", cart.ID, input.ProductID).First(&existingItem).Error
-	if err == nil {
-		// Product exists, update quantity
-		existingItem.Quantity += int(input.Quantity)
-		if err := database.DB.Save(&existingItem).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
-			return
-		}
-	} else {
-		// Add new item
-		newItem := models.CartItem{
-			CartID:    cart.ID,
-			ProductID: input.ProductID,
-			Quantity:  int(input.Quantity),
-		}
-		if err := database.DB.Create(&newItem).Error; err != nil {
-			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product to cart"})
-			return
-		}
+	if err := addProductToCart(database.DB, cart, input.ProductID, int(input.Quantity)); err != nil {
+		respondCartError(ctx, err, "Failed to add product to cart")
+		return
 	}
 
 	// Reload the cart with items
@@ -91,25 +63,29 @@ func UpdateCartItem(ctx *gin.Context) {
 		return
 	}
 
-	userIDValue, exists := ctx.Get("id")
-	if !exists {
+	owner, err := cartOwnerFromContext(ctx)
+	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
-	userID := userIDValue.(uint)
 
-	var cart models.Cart
-	if err := database.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	cart, err := findCart(database.DB, owner)
+	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
 		return
 	}
 
 	var item models.CartItem
-	if err := database.DB.Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&item).Error; err != nil {
+	if err := database.DB.Preload("Product").Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&item).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
 		return
 	}
 
+	if err := validateCartQuantity(item.Product, int(input.Quantity)); err != nil {
+		respondCartError(ctx, err, "Failed to update cart item")
+		return
+	}
+
 	item.Quantity = int(input.Quantity)
 	if err := database.DB.Save(&item).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
@@ -128,14 +104,18 @@ func UpdateCartItem(ctx *gin.Context) {
 }
 
 func GetOwnCart(ctx *gin.Context) {
-	userID, err := getIDFromContext(ctx)
+	owner, err := cartOwnerFromContext(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var cart models.Cart
-	err = database.DB.Preload("Items.Product").Where("user_id = ?", userID).First(&cart).Error
+	// Produk yang sudah dihapus tetap di-load supaya bisa diberi warning
+	cart, err := findCart(database.DB, owner)
+	if err == nil {
+		err = database.DB.Preload("Items.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+			First(&cart, cart.ID).Error
+	}
 
 	if err != nil {
 		// Jika cart tidak ditemukan, kembalikan cart kosong (atau buat baru)
@@ -143,19 +123,79 @@ func GetOwnCart(ctx *gin.Context) {
 		// Jika tidak ditemukan, anggap cart kosong
 		ctx.JSON(http.StatusOK, gin.H{
 			"message": "Cart is empty",
-			"cart":    models.Cart{UserID: userID, Items: []models.CartItem{}},
+			"cart":    models.Cart{UserID: owner.userIDPtr(), Items: []models.CartItem{}},
 		})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Cart retrieved successfully",
-		"cart":    cart,
+		"message":  "Cart retrieved successfully",
+		"cart":     cart,
+		"summary":  cartSummary(cart.Items),
+		"warnings": cartWarnings(cart.Items),
+	})
+}
+
+// Pindahkan item ke daftar saved-for-later
+func SaveCartItemForLater(ctx *gin.Context) {
+	setCartItemSaved(ctx, true)
+}
+
+// Kembalikan item saved-for-later ke cart aktif
+func MoveSavedItemToCart(ctx *gin.Context) {
+	setCartItemSaved(ctx, false)
+}
+
+func setCartItemSaved(ctx *gin.Context, saved bool) {
+	itemID, err := strconv.ParseUint(ctx.Param("itemId"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	owner, err := cartOwnerFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	cart, err := findCart(database.DB, owner)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
+		return
+	}
+
+	var item models.CartItem
+	if err := database.DB.Preload("Product").Where("id = ? AND cart_id = ?", itemID, cart.ID).First(&item).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart item not found"})
+		return
+	}
+
+	// Item yang kembali ke cart aktif harus memenuhi aturan stok / max-per-order
+	if !saved {
+		if err := validateCartQuantity(item.Product, item.Quantity); err != nil {
+			respondCartError(ctx, err, "Failed to move item to cart")
+			return
+		}
+	}
+
+	if err := database.DB.Model(&item).Update("saved_for_later", saved).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update cart item"})
+		return
+	}
+
+	message := "Item moved to cart successfully"
+	if saved {
+		message = "Item saved for later successfully"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"item":    item,
 	})
 }
 
 func RemoveCartItem(ctx *gin.Context) {
-	userID, err := getIDFromContext(ctx)
+	owner, err := cartOwnerFromContext(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
@@ -168,8 +208,8 @@ func RemoveCartItem(ctx *gin.Context) {
 		return
 	}
 
-	var cart models.Cart
-	if err := database.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	cart, err := findCart(database.DB, owner)
+	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
 		return
 	}
@@ -193,19 +233,20 @@ func RemoveCartItem(ctx *gin.Context) {
 }
 
 func ClearCart(ctx *gin.Context) {
-	userID, err := getIDFromContext(ctx)
+	owner, err := cartOwnerFromContext(ctx)
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var cart models.Cart
-	if err := database.DB.Where("user_id = ?", userID).First(&cart).Error; err != nil {
+	cart, err := findCart(database.DB, owner)
+	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Cart not found"})
 		return
 	}
 
-	result := database.DB.Where("cart_id = ?", cart.ID).Delete(&models.CartItem{})
+	// Item saved-for-later tidak ikut dihapus
+	result := database.DB.Where("cart_id = ? AND saved_for_later = ?", cart.ID, false).Delete(&models.CartItem{})
 
 	if result.Error != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clear cart items"})
@@ -217,3 +258,155 @@ func ClearCart(ctx *gin.Context) {
 		"deleted_count": result.RowsAffected,
 	})
 }
+
+var errProductNotFound = errors.New("product not found")
+
+// Error validasi quantity cart (stok / batas per order / produk nonaktif)
+type cartQuantityError struct {
+	Message   string
+	Available int
+}
+
+func (e *cartQuantityError) Error() string { return e.Message }
+
+// Cek quantity terhadap stok dan batas max-per-order produk
+func validateCartQuantity(product models.Product, quantity int) error {
+	if !product.IsActive || product.DeletedAt.Valid {
+		return &cartQuantityError{Message: "Product is not available", Available: 0}
+	}
+	if quantity > product.StockQuantity {
+		return &cartQuantityError{
+			Message:   fmt.Sprintf("Only %d item(s) of %s left in stock", product.StockQuantity, product.Name),
+			Available: product.StockQuantity,
+		}
+	}
+	if product.MaxPerOrder > 0 && quantity > product.MaxPerOrder {
+		return &cartQuantityError{
+			Message:   fmt.Sprintf("Maximum %d item(s) of %s per order", product.MaxPerOrder, product.Name),
+			Available: product.MaxPerOrder,
+		}
+	}
+	return nil
+}
+
+func respondCartError(ctx *gin.Context, err error, fallback string) {
+	var quantityErr *cartQuantityError
+	switch {
+	case err == errProductNotFound:
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.As(err, &quantityErr):
+		ctx.JSON(http.StatusConflict, gin.H{"error": quantityErr.Message, "available": quantityErr.Available})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// Ringkasan cart aktif; item saved-for-later dan produk yang sudah tidak
+// tersedia tidak dihitung ke total
+func cartSummary(items []models.CartItem) gin.H {
+	var itemCount, savedCount int
+	var subtotal int64
+	for _, item := range items {
+		if item.SavedForLater {
+			savedCount++
+			continue
+		}
+		product := item.Product
+		if product.ID == 0 || product.DeletedAt.Valid || !product.IsActive {
+			continue
+		}
+		itemCount += item.Quantity
+		subtotal += int64(item.Quantity) * product.Price
+	}
+	return gin.H{
+		"item_count":  itemCount,
+		"subtotal":    subtotal,
+		"saved_count": savedCount,
+	}
+}
+
+// Warning untuk item yang harganya berubah sejak dimasukkan ke cart,
+// stoknya habis/kurang, atau produknya sudah tidak tersedia
+func cartWarnings(items []models.CartItem) []gin.H {
+	warnings := []gin.H{}
+	for _, item := range items {
+		product := item.Product
+		base := gin.H{"item_id": item.ID, "product_id": item.ProductID, "product_name": product.Name}
+		warn := func(code, message string, extra gin.H) {
+			w := gin.H{"code": code, "message": message}
+			for k, v := range base {
+				w[k] = v
+			}
+			for k, v := range extra {
+				w[k] = v
+			}
+			warnings = append(warnings, w)
+		}
+
+		if product.ID == 0 || product.DeletedAt.Valid || !product.IsActive {
+			warn("unavailable", "This product is no longer available", nil)
+			continue
+		}
+		if product.StockQuantity <= 0 {
+			warn("out_of_stock", "This product is out of stock", nil)
+		} else if item.Quantity > product.StockQuantity {
+			warn("insufficient_stock", fmt.Sprintf("Only %d item(s) left in stock", product.StockQuantity),
+				gin.H{"available": product.StockQuantity})
+		}
+		if item.PriceAtAdd > 0 && item.PriceAtAdd != product.Price {
+			warn("price_changed", "The price of this product has changed since it was added",
+				gin.H{"old_price": item.PriceAtAdd, "new_price": product.Price})
+		}
+	}
+	return warnings
+}
+
+// Tambah produk ke cart milik user (cart dibuat jika belum ada).
+// Dipakai oleh fitur lain yang memasukkan produk ke cart user (wishlist, dll).
+// Item ditulis lewat tx supaya bisa digabung dengan perubahan lain.
+func addProductToUserCart(tx *gorm.DB, userID, productID uint, quantity int) (models.Cart, error) {
+	cart, err := findOrCreateCart(tx, cartOwner{UserID: userID})
+	if err != nil {
+		return cart, err
+	}
+	return cart, addProductToCart(tx, cart, productID, quantity)
+}
+
+// Aturan yang sama dengan AddProductToCart: quantity ditambahkan jika
+// produk sudah ada di cart, jika belum dibuat item baru. Total quantity
+// divalidasi terhadap stok dan batas max-per-order.
+func addProductToCart(tx *gorm.DB, cart models.Cart, productID uint, quantity int) error {
+	// Check if product exists
+	var product models.Product
+	if err := tx.First(&product, productID).Error; err != nil {
+		return errProductNotFound
+	}
+
+	// Check if product already in cart
+	var existingItem models.CartItem
+	err := tx.Where("cart_id = ? AND product_id = ?", cart.ID, productID).First(&existingItem).Error
+	if err == nil {
+		if err := validateCartQuantity(product, existingItem.Quantity+quantity); err != nil {
+			return err
+		}
+		// Product exists, update quantity. PriceAtAdd tetap harga awal supaya
+		// peringatan price_changed di cartWarnings tidak hilang.
+		// Item yang sebelumnya saved-for-later kembali ke cart aktif.
+		existingItem.Quantity += quantity
+		existingItem.SavedForLater = false
+		return tx.Save(&existingItem).Error
+	}
+
+	if err := validateCartQuantity(product, quantity); err != nil {
+		return err
+	}
+
+	// Add new item
+	newItem := models.CartItem{
+		CartID:     cart.ID,
+		ProductID:  productID,
+		Quantity:   quantity,
+		PriceAtAdd: product.Price,
+	}
+	return tx.Create(&newItem).Error
+}
@@ -0,0 +1,152 @@
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Pemilik cart: user yang login atau guest (token dari cookie guest_cart)
+type cartOwner struct {
+	UserID     uint
+	GuestToken string
+}
+
+func (o cartOwner) userIDPtr() *uint {
+	if o.UserID == 0 {
+		return nil
+	}
+	id := o.UserID
+	return &id
+}
+
+// Ambil pemilik cart dari context (diisi OptionalAuth/AuthMiddleware dan GuestCart)
+func cartOwnerFromContext(ctx *gin.Context) (cartOwner, error) {
+	if userID, err := getIDFromContext(ctx); err == nil {
+		return cartOwner{UserID: userID}, nil
+	}
+	if token := ctx.GetString("guest_token"); token != "" {
+		return cartOwner{GuestToken: token}, nil
+	}
+	return cartOwner{}, fmt.Errorf("no cart owner in context")
+}
+
+// findCart dan findOrCreateCart menerima tx supaya cart baru ikut
+// di-rollback bersama perubahan lain dalam transaksi yang sama
+func findCart(tx *gorm.DB, owner cartOwner) (models.Cart, error) {
+	var cart models.Cart
+	query := tx
+	if owner.UserID != 0 {
+		query = query.Where("user_id = ?", owner.UserID)
+	} else {
+		query = query.Where("guest_token = ?", owner.GuestToken)
+	}
+	err := query.First(&cart).Error
+	return cart, err
+}
+
+func findOrCreateCart(tx *gorm.DB, owner cartOwner) (models.Cart, error) {
+	cart, err := findCart(tx, owner)
+	if err == nil {
+		return cart, nil
+	}
+
+	// Create new cart if not found
+	cart = models.Cart{UserID: owner.userIDPtr()}
+	if owner.UserID == 0 {
+		token := owner.GuestToken
+		cart.GuestToken = &token
+	}
+	return cart, tx.Create(&cart).Error
+}
+
+// Gabungkan guest cart (dari cookie) ke cart user setelah login/register.
+// Quantity dijumlahkan dan dibatasi stok serta max-per-order produk;
+// guest cart lalu dihapus.
+func mergeGuestCart(ctx *gin.Context, userID uint) {
+	cookie, err := ctx.Cookie(utils.GuestCartCookie)
+	if err != nil {
+		return
+	}
+	// Cookie guest tidak dipakai lagi setelah login
+	ctx.SetCookie(utils.GuestCartCookie, "", -1, "/", "", false, true)
+
+	token, ok := utils.VerifyGuestToken(cookie, []byte(config.AppConfig.CartSecret))
+	if !ok {
+		return
+	}
+
+	guestCart, err := findCart(database.DB, cartOwner{GuestToken: token})
+	if err != nil {
+		return
+	}
+
+	userCart, err := findOrCreateCart(database.DB, cartOwner{UserID: userID})
+	if err != nil {
+		fmt.Println("Failed to merge guest cart:", err)
+		return
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var guestItems []models.CartItem
+		if err := tx.Preload("Product").Where("cart_id = ?", guestCart.ID).Find(&guestItems).Error; err != nil {
+			return err
+		}
+
+		for _, guestItem := range guestItems {
+			if guestItem.Product.ID == 0 || !guestItem.Product.IsActive {
+				continue // produk sudah dihapus / nonaktif
+			}
+
+			var item models.CartItem
+			exists := tx.Where("cart_id = ? AND product_id = ?", userCart.ID, guestItem.ProductID).First(&item).Error == nil
+
+			quantity := guestItem.Quantity
+			if exists {
+				quantity += item.Quantity
+			}
+			if quantity > guestItem.Product.StockQuantity {
+				quantity = guestItem.Product.StockQuantity
+			}
+			if max := guestItem.Product.MaxPerOrder; max > 0 && quantity > max {
+				quantity = max
+			}
+
+			if exists {
+				if quantity <= 0 {
+					continue
+				}
+				if err := tx.Model(&item).Update("quantity", quantity).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if quantity <= 0 {
+				continue
+			}
+			newItem := models.CartItem{
+				CartID:        userCart.ID,
+				ProductID:     guestItem.ProductID,
+				Quantity:      quantity,
+				PriceAtAdd:    guestItem.PriceAtAdd,
+				SavedForLater: guestItem.SavedForLater,
+			}
+			if err := tx.Create(&newItem).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", guestCart.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&guestCart).Error
+	})
+	if err != nil {
+		fmt.Println("Failed to merge guest cart:", err)
+	}
+}
@@ -22,14 +22,30 @@ func CreateCategory(ctx *gin.Context) {
 	slug := strings.ToLower(strings.ReplaceAll(input.Name, " ", "-"))
 
 	var existing models.Category
-	if err := database.DB.Where("slug = ?", slug).First(&existing).Error; err == nil {
+	if err := database.DB.Unscoped().Where("slug = ? OR name = ?", slug, input.Name).First(&existing).Error; err == nil {
+		if existing.DeletedAt.Valid {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Category already exists in trash, restore it instead", "id": existing.ID})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Category already exists"})
 		return
 	}
 
+	if input.ParentID != nil && *input.ParentID == 0 {
+		input.ParentID = nil
+	}
+	if input.ParentID != nil {
+		var parent models.Category
+		if err := database.DB.First(&parent, *input.ParentID).Error; err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent category ID"})
+			return
+		}
+	}
+
 	category := models.Category{
 		Name:      input.Name,
 		Slug:      slug,
+		ParentID:  input.ParentID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -48,7 +64,12 @@ func CreateCategory(ctx *gin.Context) {
 func GetAllCategories(ctx *gin.Context) {
 	var categories []models.Category
 
-	if err := database.DB.Find(&categories).Error; err != nil {
+	query := database.DB
+	if wantsTrashed(ctx, models.PermCategoriesRead) {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if err := query.Find(&categories).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
 		return
 	}
@@ -58,6 +79,18 @@ func GetAllCategories(ctx *gin.Context) {
 	})
 }
 
+func GetCategoryTree(ctx *gin.Context) {
+	index, err := loadCategoryIndex()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve categories"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"categories": buildCategoryTree(index),
+	})
+}
+
 func GetCategories(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 
@@ -78,6 +111,7 @@ func GetCategories(ctx *gin.Context) {
 		"id": category.ID,
 		"name": category.Name,
 		"slug": category.Slug,
+		"parent_id": category.ParentID,
 		"created_at": category.CreatedAt,
 		"updated_at": category.UpdatedAt,
 	})
@@ -92,7 +126,8 @@ func DeleteCategory(ctx *gin.Context) {
 		return
 	}
 
-	// Delete the category
+	// Soft delete: sub-category tetap menunjuk ke category ini (tampil sebagai
+	// root selama category ada di trash) supaya hierarki utuh saat di-restore
 	if err := database.DB.Delete(&category).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category"})
 		return
@@ -104,6 +139,41 @@ func DeleteCategory(ctx *gin.Context) {
 	})
 }
 
+func RestoreCategory(ctx *gin.Context) {
+	var category models.Category
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&category, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Deleted category not found"})
+		return
+	}
+
+	updateMap := map[string]interface{}{"deleted_at": nil}
+
+	// Parent bisa saja sudah dihapus atau dipindah ke bawah category ini selama di trash
+	if category.ParentID != nil {
+		index, err := loadCategoryIndex()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+			return
+		}
+		index[category.ID] = category
+		if _, ok := index[*category.ParentID]; !ok || categoryParentCreatesCycle(index, category.ID, *category.ParentID) {
+			updateMap["parent_id"] = nil
+		}
+	}
+
+	if err := database.DB.Unscoped().Model(&category).Updates(updateMap).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore category"})
+		return
+	}
+
+	database.DB.First(&category, category.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Category restored successfully",
+		"category": category,
+	})
+}
+
 func UpdateCategories(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 	categoryID, err := strconv.ParseUint(idParam, 10, 64)
@@ -128,9 +198,33 @@ func UpdateCategories(ctx *gin.Context) {
 
 	if input.Name != "" {
 		updateMap["name"] = input.Name
+		updateMap["slug"] = strings.ToLower(strings.ReplaceAll(input.Name, " ", "-"))
+	}
+	if input.Slug != "" {
 		updateMap["slug"] = input.Slug
 	}
 
+	if input.ParentID != nil {
+		if *input.ParentID == 0 {
+			updateMap["parent_id"] = nil
+		} else {
+			index, err := loadCategoryIndex()
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update category"})
+				return
+			}
+			if _, ok := index[*input.ParentID]; !ok {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid parent category ID"})
+				return
+			}
+			if categoryParentCreatesCycle(index, category.ID, *input.ParentID) {
+				ctx.JSON(http.StatusBadRequest, gin.H{"error": "Category cannot be moved under itself or its descendants"})
+				return
+			}
+			updateMap["parent_id"] = *input.ParentID
+		}
+	}
+
 	if len(updateMap) == 0 {
 		ctx.JSON(http.StatusOK, gin.H{"message": "No changes submitted", "category": category})
 		return
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"sort"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+)
+
+// Ambil semua category sekaligus lalu index berdasarkan ID.
+// Jumlah category kecil, jadi traversal dilakukan di memory.
+func loadCategoryIndex() (map[uint]models.Category, error) {
+	var categories []models.Category
+	if err := database.DB.Order("name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	index := make(map[uint]models.Category, len(categories))
+	for _, c := range categories {
+		index[c.ID] = c
+	}
+	return index, nil
+}
+
+// Bangun tree dari index; category tanpa parent menjadi root.
+func buildCategoryTree(index map[uint]models.Category) []models.Category {
+	childrenOf := make(map[uint][]uint)
+	var rootIDs []uint
+	for _, c := range index {
+		if c.ParentID == nil {
+			rootIDs = append(rootIDs, c.ID)
+			continue
+		}
+		if _, ok := index[*c.ParentID]; !ok {
+			// parent sudah hilang, tampilkan sebagai root
+			rootIDs = append(rootIDs, c.ID)
+			continue
+		}
+		childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c.ID)
+	}
+
+	var build func(id uint) models.Category
+	build = func(id uint) models.Category {
+		node := index[id]
+		node.Children = []models.Category{}
+		for _, childID := range childrenOf[id] {
+			node.Children = append(node.Children, build(childID))
+		}
+		sortCategoriesByName(node.Children)
+		return node
+	}
+
+	tree := make([]models.Category, 0, len(rootIDs))
+	for _, id := range rootIDs {
+		tree = append(tree, build(id))
+	}
+	sortCategoriesByName(tree)
+	return tree
+}
+
+func sortCategoriesByName(categories []models.Category) {
+	sort.Slice(categories, func(i, j int) bool { return categories[i].Name < categories[j].Name })
+}
+
+// ID category beserta seluruh turunannya (anak, cucu, dst).
+func categoryWithDescendantIDs(index map[uint]models.Category, rootID uint) []uint {
+	childrenOf := make(map[uint][]uint)
+	for _, c := range index {
+		if c.ParentID != nil {
+			childrenOf[*c.ParentID] = append(childrenOf[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, childID := range childrenOf[ids[i]] {
+			if !visited[childID] {
+				visited[childID] = true
+				ids = append(ids, childID)
+			}
+		}
+	}
+	return ids
+}
+
+// Breadcrumb dari root sampai category yang diberikan.
+func categoryBreadcrumbs(index map[uint]models.Category, categoryID uint) []models.Breadcrumb {
+	var trail []models.Breadcrumb
+	visited := make(map[uint]bool)
+
+	current, ok := index[categoryID]
+	for ok && !visited[current.ID] {
+		visited[current.ID] = true
+		trail = append([]models.Breadcrumb{{ID: current.ID, Name: current.Name, Slug: current.Slug}}, trail...)
+		if current.ParentID == nil {
+			break
+		}
+		current, ok = index[*current.ParentID]
+	}
+
+	if trail == nil {
+		trail = []models.Breadcrumb{}
+	}
+	return trail
+}
+
+// True jika menjadikan newParentID sebagai parent categoryID akan membentuk cycle,
+// yaitu newParentID adalah categoryID sendiri atau salah satu turunannya.
+func categoryParentCreatesCycle(index map[uint]models.Category, categoryID, newParentID uint) bool {
+	visited := make(map[uint]bool)
+	id := newParentID
+	for {
+		if id == categoryID {
+			return true
+		}
+		if visited[id] {
+			// data sudah mengandung cycle, anggap tidak valid
+			return true
+		}
+		visited[id] = true
+
+		c, ok := index[id]
+		if !ok || c.ParentID == nil {
+			return false
+		}
+		id = *c.ParentID
+	}
+}
@@ -2,6 +2,8 @@ package controllers
 
 import (
 	"fmt"
+	"slices"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,3 +20,49 @@ func getIDFromContext(ctx *gin.Context) (uint, error) {
 	}
 	return id, nil
 }
+
+// Permission dari JWT claims (diisi AuthMiddleware)
+func hasPermission(ctx *gin.Context, permission string) bool {
+	permissions, _ := ctx.Get("permissions")
+	list, _ := permissions.([]string)
+	return slices.Contains(list, permission)
+}
+
+// ?trashed=true hanya berlaku untuk user dengan permission baca resource tersebut
+func wantsTrashed(ctx *gin.Context, permission string) bool {
+	if ctx.Query("trashed") != "true" {
+		return false
+	}
+	return hasPermission(ctx, permission)
+}
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// ?page=1&per_page=20
+func paginationFromQuery(ctx *gin.Context) (page, perPage int) {
+	page, err := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err = strconv.Atoi(ctx.DefaultQuery("per_page", strconv.Itoa(defaultPerPage)))
+	if err != nil || perPage < 1 {
+		perPage = defaultPerPage
+	}
+	if perPage > maxPerPage {
+		perPage = maxPerPage
+	}
+	return page, perPage
+}
+
+func paginationMeta(page, perPage int, total int64) gin.H {
+	totalPages := (total + int64(perPage) - 1) / int64(perPage)
+	return gin.H{
+		"page":        page,
+		"per_page":    perPage,
+		"total":       total,
+		"total_pages": totalPages,
+	}
+}
@@ -18,11 +18,11 @@ func ViewHome(ctx *gin.Context) {
 func ViewLoggedin(ctx *gin.Context) {
 	id, _ := ctx.Get("id")
 	email, _ := ctx.Get("email")
-	admin, _ := ctx.Get("admin")
+	permissions, _ := ctx.Get("permissions")
 
 	ctx.HTML(http.StatusOK, "loggedin.html", gin.H{
 		"id": id,
 		"email": email,
-		"admin":  admin,
+		"permissions": permissions,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/mailer"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Verifikasi email dari link yang dikirim saat register / ganti email.
+// Link hanya berlaku untuk email yang sedang dipakai user.
+func VerifyEmail(ctx *gin.Context) {
+	var input models.VerifyEmailInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, email, ok := utils.VerifyEmailVerification(input.Token, []byte(config.AuthConfig.EmailVerificationSecret))
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil || !strings.EqualFold(user.Email, email) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired verification link"})
+		return
+	}
+
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := database.DB.Model(&user).Update("email_verified_at", &now).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify email"})
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+// Kirim ulang link verifikasi untuk user yang sedang login
+func ResendVerificationEmail(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		ctx.JSON(http.StatusOK, gin.H{"message": "Email is already verified"})
+		return
+	}
+
+	go sendVerificationEmail(user)
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Verification email sent"})
+}
+
+func sendVerificationEmail(user models.User) {
+	expiresAt := time.Now().Add(config.AuthConfig.EmailVerificationTTL)
+	token := utils.SignEmailVerification(user.ID, user.Email, expiresAt, []byte(config.AuthConfig.EmailVerificationSecret))
+	link := strings.TrimRight(config.AppConfig.AppURL, "/") + "/verify-email?token=" + url.QueryEscape(token)
+
+	mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link expires in %s.\n",
+			user.Username, link, config.AuthConfig.EmailVerificationTTL),
+	})
+}
+
+// Jika config mewajibkan, user dengan email yang belum diverifikasi tidak boleh checkout
+func requireVerifiedEmail(ctx *gin.Context, userID uint) bool {
+	if !config.AuthConfig.RequireVerifiedEmailForCheckout {
+		return true
+	}
+
+	var user models.User
+	if err := database.DB.Select("id", "email_verified_at").First(&user, userID).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return false
+	}
+	if user.EmailVerifiedAt == nil {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Please verify your email address before checking out"})
+		return false
+	}
+	return true
+}
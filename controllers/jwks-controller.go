@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Public key (RS256 / EdDSA) yang dipakai untuk verifikasi JWT
+func GetJWKS(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, gin.H{"keys": utils.PublicJWKS()})
+}
@@ -13,6 +13,11 @@ func ViewLogin(ctx *gin.Context) {
 	ctx.HTML(http.StatusOK, "login.html", gin.H{"title": "Login"})
 }
 
+// Halaman input kode 2FA setelah login lewat Google
+func ViewTwoFactorLogin(ctx *gin.Context) {
+	ctx.HTML(http.StatusOK, "2fa.html", gin.H{"title": "Two-factor authentication"})
+}
+
 func Login(ctx *gin.Context) {
 	var input models.LoginInput
 	if err := ctx.ShouldBind(&input); err != nil {
@@ -22,19 +27,42 @@ func Login(ctx *gin.Context) {
 
 	var user models.User
 	if err := database.DB.Where("username = ? OR email = ?", input.Login, input.Login).First(&user).Error; err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid username or Email"})
+		if !checkLoginAllowed(ctx, nil, input.Login) {
+			return
+		}
+		// Tetap hitung bcrypt supaya waktu respons sama dengan password salah
+		utils.CheckDummyPassword(input.Password)
+		recordLoginFailure(ctx, nil, input.Login)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": invalidLoginMessage})
+		return
+	}
+
+	if !checkLoginAllowed(ctx, &user, input.Login) {
 		return
 	}
 
 	if !utils.CheckPassword(user.Password, input.Password) {
-		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "invalid password"})
+		recordLoginFailure(ctx, &user, input.Login)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": invalidLoginMessage})
 		return
 	}
+	resetLoginFailures(user)
 
-	token, _ := utils.GenerateJWT(user.ID, user.Email, user.Admin)
+	// Akun dengan 2FA mendapat challenge token, JWT baru diberikan setelah kode diverifikasi
+	if user.TwoFactorEnabledAt != nil {
+		respondTwoFactorChallenge(ctx, user)
+		return
+	}
+
+	mergeGuestCart(ctx, user.ID)
 
-	ctx.SetCookie("auth_token", token, 3600, "/", "", false, true)
+	tokens, err := issueAuthTokens(ctx, user, input.DeviceName, false)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Login successful", "token": token})
+	tokens["message"] = "Login successful"
+	ctx.JSON(http.StatusOK, tokens)
 
 }
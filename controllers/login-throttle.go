@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Pesan yang sama untuk user tidak ditemukan dan password salah supaya
+// endpoint login tidak bisa dipakai untuk mengecek username / email
+const invalidLoginMessage = "Invalid login or password"
+
+func accountThrottleKey(user *models.User, login string) string {
+	if user != nil {
+		return fmt.Sprintf("user:%d", user.ID)
+	}
+	return "login:" + strings.ToLower(strings.TrimSpace(login))
+}
+
+func ipThrottleKey(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// Jeda yang harus ditunggu setelah failures kali gagal: base, 2x base, 4x base, ...
+// mulai dari kegagalan ke-after, maksimal LoginBackoffMax
+func loginBackoff(failures, after int) time.Duration {
+	if after <= 0 || failures < after {
+		return 0
+	}
+	cfg := config.AuthConfig
+	shift := failures - after
+	if shift > 30 || cfg.LoginBackoffBase<<shift > cfg.LoginBackoffMax || cfg.LoginBackoffBase<<shift <= 0 {
+		return cfg.LoginBackoffMax
+	}
+	return cfg.LoginBackoffBase << shift
+}
+
+// Sisa waktu tunggu untuk satu throttle key; 0 jika boleh mencoba
+func throttleWait(key string, after int, now time.Time) time.Duration {
+	var throttle models.LoginThrottle
+	if err := database.DB.Where("throttle_key = ?", key).First(&throttle).Error; err != nil {
+		return 0
+	}
+	var wait time.Duration
+	if throttle.LockedUntil != nil {
+		wait = throttle.LockedUntil.Sub(now)
+	}
+	if throttle.LastFailureAt.Before(now.Add(-config.AuthConfig.LoginAttemptWindow)) {
+		return wait
+	}
+	if backoff := throttle.LastFailureAt.Add(loginBackoff(throttle.Failures, after)).Sub(now); backoff > wait {
+		wait = backoff
+	}
+	return wait
+}
+
+// Cek lockout akun dan backoff akun / IP. Mengirim 429 dengan Retry-After
+// dan mengembalikan false jika percobaan login harus ditolak.
+func checkLoginAllowed(ctx *gin.Context, user *models.User, login string) bool {
+	now := time.Now()
+
+	var wait time.Duration
+	if user != nil && user.LockedUntil != nil && user.LockedUntil.After(now) {
+		wait = user.LockedUntil.Sub(now)
+	}
+	if w := throttleWait(accountThrottleKey(user, login), config.AuthConfig.LoginBackoffAfter, now); w > wait {
+		wait = w
+	}
+	if w := throttleWait(ipThrottleKey(ctx), config.AuthConfig.LoginIPBackoffAfter, now); w > wait {
+		wait = w
+	}
+	if wait <= 0 {
+		return true
+	}
+
+	ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	ctx.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many failed login attempts, try again later"})
+	return false
+}
+
+// Tambah penghitung gagal; penghitung yang sudah lewat LoginAttemptWindow mulai dari 1 lagi
+func incrementLoginFailures(key string, now time.Time) int {
+	windowStart := now.Add(-config.AuthConfig.LoginAttemptWindow)
+
+	// Urutan assignment penting: failures dihitung dari last_failure_at yang lama
+	database.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "throttle_key"}},
+		DoUpdates: []clause.Assignment{
+			{Column: clause.Column{Name: "failures"}, Value: gorm.Expr("IF(last_failure_at < ?, 1, failures + 1)", windowStart)},
+			{Column: clause.Column{Name: "last_failure_at"}, Value: now},
+		},
+	}).Create(&models.LoginThrottle{ThrottleKey: key, Failures: 1, LastFailureAt: now})
+
+	var throttle models.LoginThrottle
+	database.DB.Where("throttle_key = ?", key).First(&throttle)
+	return throttle.Failures
+}
+
+// Catat login gagal untuk akun dan IP. Akun dikunci sementara jika
+// kegagalan berturut-turut mencapai LoginLockoutThreshold.
+func recordLoginFailure(ctx *gin.Context, user *models.User, login string) {
+	now := time.Now()
+	incrementLoginFailures(ipThrottleKey(ctx), now)
+	failures := incrementLoginFailures(accountThrottleKey(user, login), now)
+
+	threshold := config.AuthConfig.LoginLockoutThreshold
+	if threshold <= 0 || failures < threshold {
+		return
+	}
+
+	lockedUntil := now.Add(config.AuthConfig.LoginLockoutDuration)
+
+	// Login yang tidak cocok dengan akun dikunci dengan cara yang sama,
+	// supaya Retry-After tidak membocorkan apakah akunnya ada
+	if user == nil {
+		database.DB.Model(&models.LoginThrottle{}).
+			Where("throttle_key = ?", accountThrottleKey(nil, login)).
+			Updates(map[string]interface{}{"failures": 0, "locked_until": &lockedUntil})
+		return
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", user.ID, now).
+		Update("locked_until", &lockedUntil)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return
+	}
+
+	// Setelah lockout berakhir user mulai lagi dari nol
+	database.DB.Where("throttle_key = ?", accountThrottleKey(user, login)).Delete(&models.LoginThrottle{})
+	writeAuditLog(ctx, models.AuditAccountLocked, &user.ID, nil,
+		fmt.Sprintf("%d failed login attempts, locked until %s", failures, lockedUntil.Format(time.RFC3339)))
+}
+
+// Login berhasil: reset penghitung akun. Penghitung IP dibiarkan supaya
+// satu akun valid tidak bisa dipakai untuk menghapus jejak tebakan ke akun lain.
+func resetLoginFailures(user models.User) {
+	database.DB.Where("throttle_key = ?", accountThrottleKey(&user, "")).Delete(&models.LoginThrottle{})
+}
+
+func writeAuditLog(ctx *gin.Context, action string, userID, actorID *uint, details string) {
+	entry := models.AuditLog{
+		Action:    action,
+		UserID:    userID,
+		ActorID:   actorID,
+		IPAddress: ctx.ClientIP(),
+		Details:   details,
+	}
+	if err := database.DB.Create(&entry).Error; err != nil {
+		fmt.Println("Failed to write audit log:", err)
+	}
+}
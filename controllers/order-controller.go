@@ -1,13 +1,17 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ASaifaji/as-gin-ecommerce/database"
 	"github.com/ASaifaji/as-gin-ecommerce/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func CreateOrder(ctx *gin.Context) {
@@ -17,12 +21,16 @@ func CreateOrder(ctx *gin.Context) {
 		return
 	}
 
+	if !requireVerifiedEmail(ctx, userID) {
+		return
+	}
+
 	fmt.Printf("User ID %d mencoba membuat order\n", userID)
 
 	// TO DO :
-	// a. Ambil data keranjang (Cart) pengguna dari DB
+	// a. Ambil data keranjang (Cart) pengguna dari DB (hanya item dengan saved_for_later = false)
 	// b. Cek stok produk.
-	// c. Buat Order dan Order Items baru.
+	// c. Buat Order dan Order Items baru (simpan snapshot harga; nama dan SKU diisi OrderItem.BeforeCreate).
 	// d. Kosongkan keranjang
 
 	//Respon sukses
@@ -39,17 +47,25 @@ func GetAllOwnOrders(ctx *gin.Context) {
 		return
 	}
 
-	var orders []models.Order
-	// 1. Ambil order milik user dari database
-	if err := database.DB.Where("user_id = ?", userID).Find(&orders).Error; err != nil {
+	// 1. Filter status / tanggal dari query
+	query, err := filterOrders(ctx, database.DB.Model(&models.Order{}).Where("user_id = ?", userID))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 2. Ambil order milik user beserta item-nya
+	orders, pagination, err := paginateOrders(ctx, query)
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
 		return
 	}
 
-	// 2. Respons Sukses
+	// 3. Respons Sukses
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Successfully fetched user orders",
-		"orders":  orders,
+		"message":    "Successfully fetched user orders",
+		"orders":     orders,
+		"pagination": pagination,
 	})
 }
 
@@ -68,12 +84,13 @@ func GetOrderDetail(ctx *gin.Context) {
 	}
 	
 	var order models.Order
-	if err := database.DB.Preload("OrderItems").First(&order, orderID).Error; err != nil { 
+	// Produk yang sudah dihapus (soft delete) tetap ditampilkan di riwayat order
+	if err := database.DB.Preload("Items.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).First(&order, orderID).Error; err != nil { 
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
 		return
 	}
 
-	if order.UserID != userID && !userIDIsAdmin(userID) {
+	if order.UserID != userID && !hasPermission(ctx, models.PermOrdersRead) {
 		ctx.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
@@ -84,19 +101,210 @@ func GetOrderDetail(ctx *gin.Context) {
 	})
 }
 
+// Salin item order lama ke cart user dengan aturan AddProductToCart.
+// Item yang tidak bisa ditambahkan dilaporkan di "skipped", perubahan
+// harga sejak order dibuat dilaporkan di "price_changes".
+func ReorderOrder(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	orderID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID format"})
+		return
+	}
+
+	var order models.Order
+	if err := database.DB.Preload("Items.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("id = ? AND user_id = ?", orderID, userID).
+		First(&order).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	var cart models.Cart
+	added := []gin.H{}
+	skipped := []gin.H{}
+	priceChanges := []gin.H{}
+	// Semua item ditambahkan dalam satu transaksi: jika gagal di tengah,
+	// cart tidak terisi sebagian dan retry tidak menggandakan item
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		cart, err = findOrCreateCart(tx, cartOwner{UserID: userID})
+		if err != nil {
+			return err
+		}
+
+		for _, item := range order.Items {
+			product := item.Product
+			line := gin.H{
+				"order_item_id": item.ID,
+				"product_id":    item.ProductID,
+				"product_name":  product.Name,
+				"quantity":      item.Quantity,
+			}
+
+			if err := addProductToCart(tx, cart, item.ProductID, item.Quantity); err != nil {
+				var quantityErr *cartQuantityError
+				switch {
+				case err == errProductNotFound || product.ID == 0 || product.DeletedAt.Valid || !product.IsActive:
+					line["reason"] = "unavailable"
+					line["message"] = "This product is no longer available"
+				case product.StockQuantity <= 0:
+					line["reason"] = "out_of_stock"
+					line["message"] = "This product is out of stock"
+				case errors.As(err, &quantityErr):
+					line["reason"] = "quantity_limit"
+					line["message"] = quantityErr.Message
+					line["available"] = quantityErr.Available
+				default:
+					return err
+				}
+				skipped = append(skipped, line)
+				continue
+			}
+			added = append(added, line)
+
+			if product.Price != item.Price {
+				priceChanges = append(priceChanges, gin.H{
+					"product_id":   item.ProductID,
+					"product_name": product.Name,
+					"old_price":    item.Price,
+					"new_price":    product.Price,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product to cart"})
+		return
+	}
+
+	var updatedCart models.Cart
+	if err := database.DB.Preload("Items.Product").First(&updatedCart, cart.ID).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cart"})
+		return
+	}
+
+	message := "Order items added to cart successfully"
+	if len(skipped) > 0 {
+		message = "Some order items could not be added to cart"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":       message,
+		"added":         added,
+		"skipped":       skipped,
+		"price_changes": priceChanges,
+		"cart":          updatedCart,
+	})
+}
+
 func GetAllOrders(ctx *gin.Context) {
-	var orders []models.Order
-	if err := database.DB.Preload("User").Find(&orders).Error; err != nil {
+	query := database.DB.Model(&models.Order{})
+	if userIDParam := ctx.Query("user_id"); userIDParam != "" {
+		filterUserID, err := strconv.ParseUint(userIDParam, 10, 64)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		query = query.Where("user_id = ?", filterUserID)
+	}
+
+	query, err := filterOrders(ctx, query)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	orders, pagination, err := paginateOrders(ctx, query, "User")
+	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch all orders"})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "Successfully fetched all orders (Admin View)",
-		"orders":  orders,
+		"message":    "Successfully fetched all orders (Admin View)",
+		"orders":     orders,
+		"pagination": pagination,
 	})
 }
 
+// Filter order: ?status=Diproses,Dikirim&from=2024-01-01&to=2024-01-31
+// (from/to berupa tanggal YYYY-MM-DD atau RFC3339; "to" berupa tanggal berlaku sampai akhir hari)
+func filterOrders(ctx *gin.Context, query *gorm.DB) (*gorm.DB, error) {
+	if statusParam := ctx.Query("status"); statusParam != "" {
+		var statuses []string
+		for _, s := range strings.Split(statusParam, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				statuses = append(statuses, s)
+			}
+		}
+		if len(statuses) > 0 {
+			query = query.Where("status IN ?", statuses)
+		}
+	}
+
+	if fromParam := ctx.Query("from"); fromParam != "" {
+		from, _, err := parseOrderDate(fromParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date, use YYYY-MM-DD or RFC3339")
+		}
+		query = query.Where("created_at >= ?", from)
+	}
+	if toParam := ctx.Query("to"); toParam != "" {
+		to, dateOnly, err := parseOrderDate(toParam)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date, use YYYY-MM-DD or RFC3339")
+		}
+		if dateOnly {
+			query = query.Where("created_at < ?", to.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("created_at <= ?", to)
+		}
+	}
+
+	return query, nil
+}
+
+func parseOrderDate(value string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// Order terbaru dulu, dengan item dan produknya (produk yang sudah dihapus tetap di-load)
+func paginateOrders(ctx *gin.Context, query *gorm.DB, preloads ...string) ([]models.Order, gin.H, error) {
+	page, perPage := paginationFromQuery(ctx)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	for _, preload := range preloads {
+		query = query.Preload(preload)
+	}
+
+	orders := []models.Order{}
+	err := query.
+		Preload("Items.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&orders).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return orders, paginationMeta(page, perPage, total), nil
+}
+
 func UpdateOrderStatus(ctx *gin.Context) {
 	orderIdStr := ctx.Param("id")
 	orderID, err := strconv.ParseUint(orderIdStr, 10, 64)
@@ -131,8 +339,4 @@ func UpdateOrderStatus(ctx *gin.Context) {
 		"message": "Order status updated successfully",
 		"order": order,
 	})
-}
-
-func userIDIsAdmin(id uint) bool {
-	return false 
 }
\ No newline at end of file
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/mailer"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Kirim link reset password. Respons selalu sama supaya tidak bisa dipakai
+// untuk mengecek apakah sebuah email terdaftar.
+func ForgotPassword(ctx *gin.Context) {
+	var input models.ForgotPasswordInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", strings.TrimSpace(input.Email)).First(&user).Error; err == nil {
+		// Dikirim di background supaya waktu respons tidak berbeda
+		go sendPasswordResetEmail(user)
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "If the email is registered, a password reset link has been sent",
+	})
+}
+
+func sendPasswordResetEmail(user models.User) {
+	token, err := utils.NewOpaqueToken()
+	if err != nil {
+		fmt.Println("Failed to generate password reset token:", err)
+		return
+	}
+
+	// Link reset sebelumnya tidak berlaku lagi
+	now := time.Now()
+	database.DB.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", user.ID).
+		Update("used_at", &now)
+
+	record := models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(config.AuthConfig.PasswordResetTTL),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		fmt.Println("Failed to store password reset token:", err)
+		return
+	}
+
+	link := strings.TrimRight(config.AppConfig.AppURL, "/") + "/reset-password?token=" + url.QueryEscape(token)
+	mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nWe received a request to reset your password. "+
+			"Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link expires in %s and can only be used once. "+
+			"If you did not request this, you can ignore this email.\n",
+			user.Username, link, config.AuthConfig.PasswordResetTTL),
+	})
+}
+
+// Ganti password memakai token dari email. Semua session user dicabut
+// sehingga device lain harus login ulang dengan password baru.
+func ResetPassword(ctx *gin.Context) {
+	var input models.ResetPasswordInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var record models.PasswordResetToken
+	err := database.DB.
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", utils.HashToken(input.Token), time.Now()).
+		First(&record).Error
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, record.UserID).Error; err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+
+	hashed, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	// Token dipakai, password diganti dan semua session dicabut sekaligus:
+	// jika salah satu gagal, token tetap bisa dipakai lagi
+	errTokenUsed := errors.New("reset token already used")
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Tandai terpakai secara kondisional supaya token tidak bisa dipakai dua kali
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL", record.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errTokenUsed
+		}
+		if err := tx.Model(&user).Update("password", hashed).Error; err != nil {
+			return err
+		}
+		return database.RevokeAllUserTokensTx(tx, user.ID)
+	})
+	if err == errTokenUsed {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset password"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Password reset successfully, please log in with your new password"})
+}
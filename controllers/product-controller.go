@@ -3,10 +3,12 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/ASaifaji/as-gin-ecommerce/database"
 	"github.com/ASaifaji/as-gin-ecommerce/models"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 func CreateProduct(ctx *gin.Context) {
@@ -34,13 +36,47 @@ func CreateProduct(ctx *gin.Context) {
 		return
 	}
 
+	if input.SKU != "" {
+		taken, err := skuTaken(database.DB, input.SKU, 0)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check SKU"})
+			return
+		}
+		if taken {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+			return
+		}
+	}
+
+	slug, err := database.UniqueProductSlug(database.DB, input.Name, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to generate product slug",
+		})
+		return
+	}
+
+	metaTitle := input.MetaTitle
+	if metaTitle == "" {
+		metaTitle = input.Name
+	}
+	metaDescription := input.MetaDescription
+	if metaDescription == "" {
+		metaDescription = truncateText(input.Description, 160)
+	}
+
 	product := models.Product{
-		Name:          input.Name,
-		Description:   input.Description,
-		Price:         input.Price,
-		StockQuantity: input.StockQuantity,
-		CategoryID:    input.CategoryID,
-		IsActive:      input.IsActive,
+		Name:            input.Name,
+		SKU:             input.SKU,
+		Slug:            slug,
+		Description:     input.Description,
+		MetaTitle:       metaTitle,
+		MetaDescription: metaDescription,
+		Price:           input.Price,
+		StockQuantity:   input.StockQuantity,
+		MaxPerOrder:     input.MaxPerOrder,
+		CategoryID:      input.CategoryID,
+		IsActive:        input.IsActive,
 	}
 
 	if err := database.DB.Create(&product).Error; err != nil {
@@ -55,9 +91,14 @@ func CreateProduct(ctx *gin.Context) {
 		"product": gin.H{
 			"id":             product.ID,
 			"name":           product.Name,
+			"sku":            product.SKU,
+			"slug":           product.Slug,
 			"description":    product.Description,
+			"meta_title":     product.MetaTitle,
+			"meta_description": product.MetaDescription,
 			"price":          product.Price,
 			"stock_quantity": product.StockQuantity,
+			"max_per_order":  product.MaxPerOrder,
 			"category_id":    product.CategoryID,
 			"is_active":      product.IsActive,
 		},
@@ -67,17 +108,106 @@ func CreateProduct(ctx *gin.Context) {
 func GetAllProducts(ctx *gin.Context) {
 	var products []models.Product
 
-	if err := database.DB.Preload("Category").Find(&products).Error; err != nil {
+	query := database.DB.Model(&models.Product{})
+	if wantsTrashed(ctx, models.PermProductsRead) {
+		query = query.Unscoped().Where("products.deleted_at IS NOT NULL")
+	}
+
+	// Filter category (ID atau slug), termasuk semua sub-category
+	if categoryParam := ctx.Query("category"); categoryParam != "" {
+		index, err := loadCategoryIndex()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
+			return
+		}
+
+		var rootID uint
+		if id, err := strconv.ParseUint(categoryParam, 10, 64); err == nil {
+			rootID = uint(id)
+		} else {
+			for _, c := range index {
+				if c.Slug == categoryParam {
+					rootID = c.ID
+					break
+				}
+			}
+		}
+		if _, ok := index[rootID]; !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+			return
+		}
+
+		query = query.Where("products.category_id IN ?", categoryWithDescendantIDs(index, rootID))
+	}
+
+	// Filter attribute: ?attr[color]=red&attr[ram]=16GB,32GB
+	for slug, raw := range ctx.QueryMap("attr") {
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		query = query.Where("products.id IN (?)", database.DB.Table("attribute_values").
+			Select("attribute_values.product_id").
+			Joins("JOIN attributes ON attributes.id = attribute_values.attribute_id").
+			Where("attributes.slug = ? AND attribute_values.value IN ?", slug, values))
+	}
+
+	query = query.Session(&gorm.Session{})
+
+	if err := query.Preload("Category").Find(&products).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch products"})
 		return
 	}
 
+	facets, err := productAttributeFacets(query.Select("products.id"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch product facets"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"products": products,
+		"facets":   facets,
 	})
 }
 
 
+// Jumlah produk per nilai attribute dari hasil filter saat ini
+func productAttributeFacets(productIDs *gorm.DB) (map[string]gin.H, error) {
+	var rows []struct {
+		Slug  string
+		Name  string
+		Value string
+		Count int64
+	}
+	err := database.DB.Table("attribute_values").
+		Select("attributes.slug, attributes.name, attribute_values.value, COUNT(DISTINCT attribute_values.product_id) AS count").
+		Joins("JOIN attributes ON attributes.id = attribute_values.attribute_id").
+		Where("attribute_values.product_id IN (?)", productIDs).
+		Group("attributes.slug, attributes.name, attribute_values.value").
+		Order("attributes.slug ASC, count DESC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	facets := make(map[string]gin.H)
+	for _, r := range rows {
+		facet, ok := facets[r.Slug]
+		if !ok {
+			facet = gin.H{"name": r.Name, "values": []gin.H{}}
+			facets[r.Slug] = facet
+		}
+		facet["values"] = append(facet["values"].([]gin.H), gin.H{"value": r.Value, "count": r.Count})
+	}
+	return facets, nil
+}
+
 func GetProductDetail(ctx *gin.Context) {
 	idParam := ctx.Param("id")
 
@@ -91,13 +221,83 @@ func GetProductDetail(ctx *gin.Context) {
 
 	var product models.Product
 
-	if err := database.DB.Preload("Category").First(&product, productID).Error; err != nil {
+	if err := database.DB.Preload("Category").Preload("Attributes.Attribute").First(&product, productID).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
 		return
 	}
 
+	respondProductDetail(ctx, product)
+}
+
+func GetProductBySlug(ctx *gin.Context) {
+	slug := ctx.Param("slug")
+
+	var product models.Product
+	if err := database.DB.Preload("Category").Preload("Attributes.Attribute").Where("slug = ?", slug).First(&product).Error; err != nil {
+		// Slug lama -> redirect permanen ke slug terbaru
+		var redirect models.ProductSlugRedirect
+		// Product yang sudah di trash tidak ikut di-preload (ID 0) -> 404
+		if err := database.DB.Preload("Product").Where("slug = ?", slug).First(&redirect).Error; err == nil && redirect.Product.ID != 0 {
+			ctx.Redirect(http.StatusMovedPermanently, "/api/products/slug/"+redirect.Product.Slug)
+			return
+		}
+
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	respondProductDetail(ctx, product)
+}
+
+func respondProductDetail(ctx *gin.Context, product models.Product) {
+	index, err := loadCategoryIndex()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load product category"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"data":        product,
+		"breadcrumbs": categoryBreadcrumbs(index, product.CategoryID),
+	})
+}
+
+// Produk terkait diambil dari tabel product_relations yang diisi job periodik
+func GetRelatedProducts(ctx *gin.Context) {
+	productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "8"))
+	if err != nil || limit <= 0 || limit > 20 {
+		limit = 8
+	}
+
+	var relations []models.ProductRelation
+	if err := database.DB.Preload("RelatedProduct.Category").
+		Joins("JOIN products ON products.id = product_relations.related_product_id").
+		Where("product_relations.product_id = ? AND products.is_active = ? AND products.deleted_at IS NULL", productID, true).
+		Order("product_relations.score DESC").
+		Limit(limit).
+		Find(&relations).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related products"})
+		return
+	}
+
+	related := make([]gin.H, 0, len(relations))
+	for _, r := range relations {
+		related = append(related, gin.H{
+			"source":            r.Source,
+			"co_purchase_count": r.CoPurchaseCount,
+			"product":           r.RelatedProduct,
+		})
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
-		"data":    product,
+		"product_id": productID,
+		"related":    related,
 	})
 }
 
@@ -124,6 +324,26 @@ func DeleteProduct(ctx *gin.Context) {
 	})
 }
 
+func RestoreProduct(ctx *gin.Context) {
+	var product models.Product
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&product, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Deleted product not found"})
+		return
+	}
+
+	if err := database.DB.Unscoped().Model(&product).Update("deleted_at", nil).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore product"})
+		return
+	}
+
+	database.DB.Preload("Category").First(&product, product.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Product restored successfully",
+		"product": product,
+	})
+}
+
 func UpdateProduct(ctx *gin.Context) {
 	id := ctx.Param("id")
 	productID, err := strconv.ParseUint(id, 10, 64)
@@ -154,17 +374,111 @@ func UpdateProduct(ctx *gin.Context) {
     if input.Price > 0 { 
         updateMap["price"] = input.Price
     }
-    if input.StockQuantity >= 0 {
-        updateMap["stock"] = input.StockQuantity
+    if input.MetaTitle != nil {
+        updateMap["meta_title"] = *input.MetaTitle
+    }
+    if input.MetaDescription != nil {
+        updateMap["meta_description"] = *input.MetaDescription
+    }
+    if input.StockQuantity != nil {
+        if *input.StockQuantity < 0 {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": "Stock cannot be negative"})
+            return
+        }
+        updateMap["stock_quantity"] = *input.StockQuantity
+    }
+    if input.MaxPerOrder != nil {
+        updateMap["max_per_order"] = *input.MaxPerOrder
     }
     if input.CategoryID > 0 {
         updateMap["category_id"] = input.CategoryID
     }
+    if input.SKU != nil {
+        if *input.SKU == "" {
+            updateMap["sku"] = nil
+        } else {
+            taken, err := skuTaken(database.DB, *input.SKU, product.ID)
+            if err != nil {
+                ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check SKU"})
+                return
+            }
+            if taken {
+                ctx.JSON(http.StatusConflict, gin.H{"error": "SKU already in use"})
+                return
+            }
+            updateMap["sku"] = *input.SKU
+        }
+    }
+    if input.IsActive != nil {
+        updateMap["is_active"] = *input.IsActive
+    }
+
+	if len(updateMap) == 0 {
+		ctx.JSON(http.StatusOK, gin.H{"message": "No changes submitted", "product": product})
+		return
+	}
+
+	before := product
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Rename -> slug baru, slug lama disimpan sebagai redirect
+		if input.Name != "" && input.Name != product.Name {
+			slug, err := renameProductSlug(tx, product, input.Name)
+			if err != nil {
+				return err
+			}
+			updateMap["slug"] = slug
+		}
+
+		return tx.Model(&product).Updates(updateMap).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
+		return
+	}
+
+	database.DB.Preload("Category").First(&product, productID)
 
-	database.DB.First(&product, productID)
+	// Notifikasi wishlist (harga turun / stok kembali) tidak menahan response
+	go notifyWishlistWatchers(before, product)
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "Product updated successfully",
 		"product": product,
 	})
-}
\ No newline at end of file
+}
+
+// Buat slug baru dari newName; slug lama disimpan sebagai redirect.
+// Mengembalikan slug yang harus disimpan ke produk.
+func renameProductSlug(tx *gorm.DB, product models.Product, newName string) (string, error) {
+	slug, err := database.UniqueProductSlug(tx, newName, product.ID)
+	if err != nil || slug == product.Slug {
+		return slug, err
+	}
+
+	if product.Slug != "" {
+		redirect := models.ProductSlugRedirect{Slug: product.Slug, ProductID: product.ID}
+		if err := tx.Where("slug = ?", product.Slug).FirstOrCreate(&redirect).Error; err != nil {
+			return "", err
+		}
+	}
+	// slug baru tidak boleh tetap menjadi redirect
+	if err := tx.Where("slug = ?", slug).Delete(&models.ProductSlugRedirect{}).Error; err != nil {
+		return "", err
+	}
+	return slug, nil
+}
+
+func skuTaken(tx *gorm.DB, sku string, productID uint) (bool, error) {
+	var count int64
+	err := tx.Unscoped().Model(&models.Product{}).Where("sku = ? AND id <> ?", sku, productID).Count(&count).Error
+	return count > 0, err
+}
+
+// Potong teks untuk meta description tanpa memotong di tengah karakter
+func truncateText(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max])
+}
@@ -0,0 +1,432 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// kolom CSV untuk import/export produk
+var productCSVColumns = []string{"sku", "slug", "name", "description", "price", "stock", "category_slug", "active"}
+
+var errImportDryRun = errors.New("dry run")
+
+type productImportRow struct {
+	Row          int
+	SKU          string
+	Slug         string
+	Name         string
+	Description  string
+	Price        int64
+	Stock        int
+	CategoryID   uint
+	CategorySlug string
+	Active       bool
+}
+
+// Import produk dari CSV (multipart field "file" atau body text/csv).
+// ?dry_run=true menjalankan semua validasi dan upsert lalu di-rollback.
+func ImportProducts(ctx *gin.Context) {
+	dryRun, _ := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+
+	var reader io.Reader = ctx.Request.Body
+	if file, err := ctx.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+			return
+		}
+		defer f.Close()
+		reader = f
+	}
+
+	rows, importErrors, err := parseProductCSV(reader)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(importErrors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "CSV contains invalid rows, nothing was imported",
+			"errors": importErrors,
+		})
+		return
+	}
+
+	var created, updated int
+	var changed []struct{ before, after models.Product }
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			before, after, isNew, err := upsertImportedProduct(tx, row)
+			if err != nil {
+				importErrors = append(importErrors, models.ProductImportError{Row: row.Row, Message: err.Error()})
+				continue
+			}
+			if isNew {
+				created++
+			} else {
+				updated++
+				changed = append(changed, struct{ before, after models.Product }{before, after})
+			}
+		}
+
+		if len(importErrors) > 0 {
+			return errors.New("import failed")
+		}
+		if dryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+
+	if len(importErrors) > 0 {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":  "CSV contains invalid rows, nothing was imported",
+			"errors": importErrors,
+		})
+		return
+	}
+	if err != nil && err != errImportDryRun {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import products"})
+		return
+	}
+
+	if !dryRun {
+		for _, c := range changed {
+			go notifyWishlistWatchers(c.before, c.after)
+		}
+	}
+
+	message := "Products imported successfully"
+	if dryRun {
+		message = "Dry run completed, no changes were saved"
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"dry_run": dryRun,
+		"total":   len(rows),
+		"created": created,
+		"updated": updated,
+	})
+}
+
+// Export seluruh katalog sebagai CSV; baris dibaca dengan cursor dan langsung
+// ditulis ke response sehingga katalog tidak dimuat sekaligus ke memory.
+func ExportProducts(ctx *gin.Context) {
+	rows, err := database.DB.Table("products").
+		Select("products.sku, products.slug, products.name, products.description, products.price, products.stock_quantity, categories.slug, products.is_active").
+		Joins("LEFT JOIN categories ON categories.id = products.category_id").
+		Where("products.deleted_at IS NULL").
+		Order("products.id ASC").
+		Rows()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export products"})
+		return
+	}
+	defer rows.Close()
+
+	ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	ctx.Header("Content-Disposition", `attachment; filename="products.csv"`)
+	ctx.Status(http.StatusOK)
+
+	w := csv.NewWriter(ctx.Writer)
+	if err := w.Write(productCSVColumns); err != nil {
+		log.Println("Failed to export products:", err)
+		abortStream(ctx)
+		return
+	}
+
+	count := 0
+	for rows.Next() {
+		var (
+			sku, slug, categorySlug *string
+			name, description       string
+			price                   int64
+			stock                   int
+			active                  bool
+		)
+		if err := rows.Scan(&sku, &slug, &name, &description, &price, &stock, &categorySlug, &active); err != nil {
+			log.Println("Failed to export product row:", err)
+			abortStream(ctx)
+			return
+		}
+
+		if err := w.Write([]string{
+			derefString(sku), derefString(slug), name, description,
+			strconv.FormatInt(price, 10), strconv.Itoa(stock),
+			derefString(categorySlug), strconv.FormatBool(active),
+		}); err != nil {
+			log.Println("Failed to export products:", err)
+			abortStream(ctx)
+			return
+		}
+
+		count++
+		if count%500 == 0 {
+			w.Flush()
+			if err := w.Error(); err != nil {
+				log.Println("Failed to export products:", err)
+				abortStream(ctx)
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Println("Failed to export products:", err)
+		abortStream(ctx)
+		return
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Println("Failed to export products:", err)
+		abortStream(ctx)
+	}
+}
+
+// Status 200 dan sebagian CSV sudah terkirim: koneksi diputus tanpa
+// mengakhiri response supaya client melihat download gagal, bukan katalog
+// yang tampak lengkap
+func abortStream(ctx *gin.Context) {
+	ctx.Abort()
+	conn, _, err := ctx.Writer.Hijack()
+	if err != nil {
+		log.Println("Failed to abort response:", err)
+		return
+	}
+	conn.Close()
+}
+
+func parseProductCSV(r io.Reader) ([]productImportRow, []models.ProductImportError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.New("CSV is empty or unreadable")
+	}
+
+	col := make(map[string]int)
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "description", "price", "stock", "category_slug"} {
+		if _, ok := col[required]; !ok {
+			return nil, nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	var categories []models.Category
+	if err := database.DB.Select("id", "slug").Find(&categories).Error; err != nil {
+		return nil, nil, errors.New("failed to load categories")
+	}
+	categoryIDs := make(map[string]uint, len(categories))
+	for _, c := range categories {
+		categoryIDs[c.Slug] = c.ID
+	}
+
+	var rows []productImportRow
+	var importErrors []models.ProductImportError
+	seenKeys := make(map[string]int)
+
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			importErrors = append(importErrors, models.ProductImportError{Row: line, Message: err.Error()})
+			continue
+		}
+
+		get := func(name string) string {
+			if i, ok := col[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		addErr := func(field, msg string) {
+			importErrors = append(importErrors, models.ProductImportError{Row: line, Field: field, Message: msg})
+		}
+
+		row := productImportRow{
+			Row:          line,
+			SKU:          get("sku"),
+			Slug:         get("slug"),
+			Name:         get("name"),
+			Description:  get("description"),
+			CategorySlug: get("category_slug"),
+			Active:       true,
+		}
+
+		if n := len([]rune(row.Name)); n < 5 || n > 255 {
+			addErr("name", "name must be between 5 and 255 characters")
+		}
+		if len([]rune(row.Description)) < 10 {
+			addErr("description", "description must be at least 10 characters")
+		}
+		if len(row.SKU) > 64 {
+			addErr("sku", "sku must be at most 64 characters")
+		}
+
+		price, err := strconv.ParseInt(get("price"), 10, 64)
+		if err != nil || price <= 0 {
+			addErr("price", "price must be a positive integer")
+		}
+		row.Price = price
+
+		stock, err := strconv.Atoi(get("stock"))
+		if err != nil || stock < 0 {
+			addErr("stock", "stock must be a non-negative integer")
+		}
+		row.Stock = stock
+
+		categoryID, ok := categoryIDs[row.CategorySlug]
+		if !ok {
+			addErr("category_slug", fmt.Sprintf("unknown category %q", row.CategorySlug))
+		}
+		row.CategoryID = categoryID
+
+		if active := get("active"); active != "" {
+			switch strings.ToLower(active) {
+			case "1", "true", "yes", "y":
+				row.Active = true
+			case "0", "false", "no", "n":
+				row.Active = false
+			default:
+				addErr("active", "active must be true or false")
+			}
+		}
+
+		// SKU / slug tidak boleh muncul dua kali di file yang sama
+		for _, key := range []string{"sku:" + row.SKU, "slug:" + row.Slug} {
+			if strings.HasSuffix(key, ":") {
+				continue
+			}
+			if first, dup := seenKeys[key]; dup {
+				addErr(strings.SplitN(key, ":", 2)[0], fmt.Sprintf("duplicate of row %d", first))
+			} else {
+				seenKeys[key] = line
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, importErrors, nil
+}
+
+// Upsert satu baris: cari berdasarkan SKU, lalu slug; jika tidak ada buat baru
+func upsertImportedProduct(tx *gorm.DB, row productImportRow) (before, after models.Product, isNew bool, err error) {
+	// Produk di trash ikut dicari supaya SKU/slug-nya tidak bentrok
+	var product models.Product
+	found := false
+	if row.SKU != "" {
+		found = tx.Unscoped().Where("sku = ?", row.SKU).First(&product).Error == nil
+	}
+	if !found && row.Slug != "" {
+		found = tx.Unscoped().Where("slug = ?", row.Slug).First(&product).Error == nil
+	}
+	if found && product.DeletedAt.Valid {
+		return before, after, false, fmt.Errorf("product %q is in trash, restore it before importing", product.Slug)
+	}
+
+	if !found {
+		slug := row.Slug
+		if slug == "" {
+			slug = row.Name
+		}
+		slug, err = database.UniqueProductSlug(tx, slug, 0)
+		if err != nil {
+			return before, after, true, err
+		}
+
+		product = models.Product{
+			Name:            row.Name,
+			SKU:             row.SKU,
+			Slug:            slug,
+			Description:     row.Description,
+			MetaTitle:       row.Name,
+			MetaDescription: truncateText(row.Description, 160),
+			Price:           row.Price,
+			StockQuantity:   row.Stock,
+			CategoryID:      row.CategoryID,
+			IsActive:        row.Active,
+		}
+		if err := tx.Create(&product).Error; err != nil {
+			return before, after, true, fmt.Errorf("failed to create product: %v", err)
+		}
+		return before, product, true, nil
+	}
+
+	before = product
+	updateMap := map[string]interface{}{
+		"name":           row.Name,
+		"description":    row.Description,
+		"price":          row.Price,
+		"stock_quantity": row.Stock,
+		"category_id":    row.CategoryID,
+		"is_active":      row.Active,
+	}
+	if row.SKU != "" && row.SKU != product.SKU {
+		taken, err := skuTaken(tx, row.SKU, product.ID)
+		if err != nil {
+			return before, after, false, fmt.Errorf("failed to check sku: %v", err)
+		}
+		if taken {
+			return before, after, false, fmt.Errorf("sku %q already used by another product", row.SKU)
+		}
+		updateMap["sku"] = row.SKU
+	}
+	if wanted := utils.Slugify(row.Slug); wanted != "" && wanted != product.Slug {
+		// Slug dari CSV dipakai apa adanya (slug lama jadi redirect); jika
+		// sudah dipakai produk lain, baris ini gagal daripada diberi akhiran
+		available, err := database.UniqueProductSlug(tx, wanted, product.ID)
+		if err != nil {
+			return before, after, false, err
+		}
+		if available != wanted {
+			return before, after, false, fmt.Errorf("slug %q already used by another product", wanted)
+		}
+		slug, err := renameProductSlug(tx, product, wanted)
+		if err != nil {
+			return before, after, false, err
+		}
+		updateMap["slug"] = slug
+	} else if row.Name != product.Name && row.Slug == "" {
+		slug, err := renameProductSlug(tx, product, row.Name)
+		if err != nil {
+			return before, after, false, err
+		}
+		updateMap["slug"] = slug
+	}
+
+	if err := tx.Model(&product).Updates(updateMap).Error; err != nil {
+		return before, after, false, fmt.Errorf("failed to update product: %v", err)
+	}
+	if err := tx.First(&after, product.ID).Error; err != nil {
+		return before, after, false, err
+	}
+	return before, after, false, nil
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -46,7 +46,7 @@ func Register(ctx *gin.Context) {
 	}
 
     cart := models.Cart{
-        UserID: User.ID,
+        UserID: &User.ID,
         CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
     }
@@ -56,9 +56,16 @@ func Register(ctx *gin.Context) {
 		return
 	}
 
-    token, _ := utils.GenerateJWT(User.ID, User.Email, User.Admin)
+    go sendVerificationEmail(User)
 
-	ctx.SetCookie("auth_token", token, 3600, "/", "", false, true)
+    mergeGuestCart(ctx, User.ID)
 
-	ctx.JSON(http.StatusOK, gin.H{"message": "Login successful", "token": token})
+    tokens, err := issueAuthTokens(ctx, User, "", false)
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+        return
+    }
+
+    tokens["message"] = "Login successful"
+    ctx.JSON(http.StatusOK, tokens)
 }
\ No newline at end of file
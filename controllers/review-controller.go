@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func CreateReview(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var input models.ReviewInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := database.DB.First(&product, productID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	// Hanya pembeli dengan order selesai yang berisi produk ini
+	var purchased int64
+	err = database.DB.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Where("orders.user_id = ? AND orders.status = ? AND order_items.product_id = ?", userID, models.OrderCompleted, product.ID).
+		Count(&purchased).Error
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify purchase"})
+		return
+	}
+	if purchased == 0 {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only customers who purchased this product can review it"})
+		return
+	}
+
+	var existing models.Review
+	if err := database.DB.Where("user_id = ? AND product_id = ?", userID, product.ID).First(&existing).Error; err == nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this product"})
+		return
+	}
+
+	review := models.Review{
+		ProductID: product.ID,
+		UserID:    userID,
+		Rating:    input.Rating,
+		Title:     input.Title,
+		Body:      input.Body,
+		Status:    models.ReviewPending,
+	}
+	if err := database.DB.Create(&review).Error; err != nil {
+		// Dua submit bersamaan lolos cek di atas, unique index menolak yang kedua
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "You have already reviewed this product"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create review"})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Review submitted and awaiting moderation",
+		"review":  review,
+	})
+}
+
+func GetProductReviews(ctx *gin.Context) {
+	productID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var product models.Product
+	if err := database.DB.First(&product, productID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var reviews []models.Review
+	if err := database.DB.Preload("User").
+		Where("product_id = ? AND status = ?", product.ID, models.ReviewApproved).
+		Order("created_at DESC").
+		Find(&reviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"rating_average": product.RatingAverage,
+		"rating_count":   product.RatingCount,
+		"reviews":        reviewResponses(reviews),
+	})
+}
+
+func GetAllReviews(ctx *gin.Context) {
+	query := database.DB.Preload("User").Order("created_at DESC")
+	if status := ctx.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var reviews []models.Review
+	if err := query.Find(&reviews).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"reviews": reviewResponses(reviews),
+	})
+}
+
+func UpdateReviewStatus(ctx *gin.Context) {
+	reviewID, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid review ID"})
+		return
+	}
+
+	var input models.UpdateReviewStatusInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var review models.Review
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Lock baris review supaya dua moderasi paralel tidak sama-sama
+		// melihat status lama dan menghitung rating dua kali
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, reviewID).Error; err != nil {
+			return err
+		}
+		if review.Status == input.Status {
+			return nil
+		}
+
+		// Rating produk di-update secara incremental, tanpa hitung ulang semua review
+		var countDelta, sumDelta int64
+		if input.Status == models.ReviewApproved {
+			countDelta, sumDelta = 1, int64(review.Rating)
+		} else if review.Status == models.ReviewApproved {
+			countDelta, sumDelta = -1, -int64(review.Rating)
+		}
+		if countDelta != 0 {
+			if err := applyProductRatingDelta(tx, review.ProductID, countDelta, sumDelta); err != nil {
+				return err
+			}
+		}
+
+		review.Status = input.Status
+		return tx.Model(&review).Update("status", input.Status).Error
+	})
+	if err == gorm.ErrRecordNotFound {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Review not found"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update review status"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Review status updated successfully",
+		"review":  review,
+	})
+}
+
+// MySQL mengevaluasi SET dari kiri ke kanan, jadi rating_average memakai
+// rating_count dan rating_sum yang sudah di-update.
+func applyProductRatingDelta(tx *gorm.DB, productID uint, countDelta, sumDelta int64) error {
+	return tx.Exec(`UPDATE products SET
+		rating_count = rating_count + ?,
+		rating_sum = rating_sum + ?,
+		rating_average = IF(rating_count > 0, rating_sum / rating_count, 0)
+		WHERE id = ?`, countDelta, sumDelta, productID).Error
+}
+
+func reviewResponses(reviews []models.Review) []gin.H {
+	result := make([]gin.H, 0, len(reviews))
+	for _, r := range reviews {
+		result = append(result, gin.H{
+			"id":         r.ID,
+			"product_id": r.ProductID,
+			"user_id":    r.UserID,
+			"username":   r.User.Username,
+			"rating":     r.Rating,
+			"title":      r.Title,
+			"body":       r.Body,
+			"status":     r.Status,
+			"created_at": r.CreatedAt,
+		})
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/gin-gonic/gin"
+)
+
+// Daftar role beserta permission-nya
+func GetAllRoles(ctx *gin.Context) {
+	var roles []models.Role
+	if err := database.DB.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func GetAllPermissions(ctx *gin.Context) {
+	var permissions []models.Permission
+	if err := database.DB.Order("name").Find(&permissions).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+// Role user dan permission efektif dari gabungan semua role
+func GetUserRoles(ctx *gin.Context) {
+	var user models.User
+	if err := database.DB.Preload("Roles").First(&user, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	permissions, err := database.UserPermissions(user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch permissions"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"user_id":     user.ID,
+		"roles":       user.Roles,
+		"permissions": permissions,
+	})
+}
+
+// Ganti seluruh role user. Access token user langsung tidak berlaku supaya
+// permission baru dipakai pada refresh berikutnya.
+func SetUserRoles(ctx *gin.Context) {
+	var input models.AssignRolesInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.Preload("Roles").First(&user, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	roles := []models.Role{}
+	if len(input.Roles) > 0 {
+		if err := database.DB.Where("name IN ?", input.Roles).Find(&roles).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch roles"})
+			return
+		}
+	}
+	if unknown := unknownRoles(input.Roles, roles); len(unknown) > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown role: " + strings.Join(unknown, ", ")})
+		return
+	}
+
+	// Jangan sampai tidak ada super_admin yang tersisa
+	if hasRole(user.Roles, models.RoleSuperAdmin) && !hasRole(roles, models.RoleSuperAdmin) {
+		var others int64
+		database.DB.Model(&models.User{}).
+			Joins("JOIN user_roles ON user_roles.user_id = users.id").
+			Joins("JOIN roles ON roles.id = user_roles.role_id").
+			Where("roles.name = ? AND users.id <> ?", models.RoleSuperAdmin, user.ID).
+			Count(&others)
+		if others == 0 {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last super admin"})
+			return
+		}
+	}
+
+	if err := database.DB.Model(&user).Association("Roles").Replace(roles); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update roles"})
+		return
+	}
+
+	if err := database.ExpireAccessTokens(user.ID); err != nil {
+		fmt.Println("Failed to expire access tokens after role change:", err)
+	}
+
+	var actorID *uint
+	if id, err := getIDFromContext(ctx); err == nil {
+		actorID = &id
+	}
+	writeAuditLog(ctx, models.AuditRolesUpdated, &user.ID, actorID, "roles: "+strings.Join(roleNames(roles), ", "))
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Roles updated successfully",
+		"user_id": user.ID,
+		"roles":   roleNames(roles),
+	})
+}
+
+// Actor hanya boleh mengelola user yang semua permission-nya juga dimiliki actor
+func canManageUser(ctx *gin.Context, targetID uint) (bool, error) {
+	permissions, err := database.UserPermissions(targetID)
+	if err != nil {
+		return false, err
+	}
+	for _, permission := range permissions {
+		if !hasPermission(ctx, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ensureCanManageUser menulis response 403/500 dan mengembalikan false jika
+// actor tidak boleh mengelola user target
+func ensureCanManageUser(ctx *gin.Context, targetID uint) bool {
+	allowed, err := canManageUser(ctx, targetID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return false
+	}
+	if !allowed {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden: user has permissions you do not have"})
+		return false
+	}
+	return true
+}
+
+func unknownRoles(names []string, found []models.Role) []string {
+	var unknown []string
+	for _, name := range names {
+		if !hasRole(found, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+func hasRole(roles []models.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func roleNames(roles []models.Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
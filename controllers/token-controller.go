@@ -0,0 +1,220 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Login baru: buat Session untuk device ini lalu terbitkan access token +
+// refresh token dan set keduanya sebagai cookie. twoFactor menandai login
+// yang sudah melewati verifikasi 2FA.
+func issueAuthTokens(ctx *gin.Context, user models.User, deviceName string, twoFactor bool) (gin.H, error) {
+	session := models.Session{
+		UserID:     user.ID,
+		TwoFactor:  twoFactor,
+		DeviceName: deviceName,
+		UserAgent:  truncateText(ctx.Request.UserAgent(), 255),
+		IPAddress:  ctx.ClientIP(),
+		LastSeenAt: time.Now(),
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	familyID, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	return issueSessionTokens(ctx, user, session, familyID)
+}
+
+func issueSessionTokens(ctx *gin.Context, user models.User, session models.Session, familyID string) (gin.H, error) {
+	refreshToken, err := utils.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	record := models.RefreshToken{
+		UserID:    user.ID,
+		SessionID: session.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(config.JWTConfig.RefreshTokenTTL),
+	}
+	if err := database.DB.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	permissions, err := database.UserPermissions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateJWT(utils.Claims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		SessionID:   session.ID,
+		TwoFactor:   session.TwoFactor,
+		Permissions: permissions,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.SetCookie(utils.AccessTokenCookie, accessToken, int(utils.AccessTokenTTL.Seconds()), "/", "", false, true)
+	ctx.SetCookie(utils.RefreshTokenCookie, refreshToken, int(config.JWTConfig.RefreshTokenTTL.Seconds()), utils.RefreshTokenCookiePath, "", false, true)
+
+	return gin.H{
+		"token":         accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(utils.AccessTokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+	}, nil
+}
+
+// Tukar refresh token dengan pasangan token baru (rotasi). Refresh token
+// yang sudah pernah dirotasi dan dipakai lagi dianggap dicuri: seluruh
+// family-nya dicabut sehingga semua device pada login tersebut harus login ulang.
+func RefreshAuthToken(ctx *gin.Context) {
+	var input models.RefreshTokenInput
+	ctx.ShouldBind(&input)
+	if input.RefreshToken == "" {
+		input.RefreshToken, _ = ctx.Cookie(utils.RefreshTokenCookie)
+	}
+	if input.RefreshToken == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "refresh_token is required"})
+		return
+	}
+
+	var record models.RefreshToken
+	if err := database.DB.Where("token_hash = ?", utils.HashToken(input.RefreshToken)).First(&record).Error; err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid refresh token"})
+		return
+	}
+
+	// Refresh token tanpa session (dibuat sebelum ada tabel sessions) harus login ulang
+	if record.RevokedAt != nil || record.ExpiresAt.Before(time.Now()) || record.SessionID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token expired or revoked"})
+		return
+	}
+	if record.UsedAt != nil {
+		database.RevokeRefreshTokenFamily(record.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please log in again"})
+		return
+	}
+
+	// Tandai terpakai secara kondisional supaya dua request paralel dengan
+	// token yang sama tidak sama-sama berhasil
+	now := time.Now()
+	result := database.DB.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", record.ID).
+		Update("used_at", &now)
+	if result.Error != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		database.RevokeRefreshTokenFamily(record.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token reuse detected, please log in again"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, record.UserID).Error; err != nil {
+		database.RevokeRefreshTokenFamily(record.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+		return
+	}
+
+	var session models.Session
+	if err := database.DB.First(&session, record.SessionID).Error; err != nil || session.RevokedAt != nil {
+		database.RevokeRefreshTokenFamily(record.FamilyID)
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked, please log in again"})
+		return
+	}
+	database.DB.Model(&session).Updates(map[string]interface{}{
+		"last_seen_at": time.Now(),
+		"ip_address":   ctx.ClientIP(),
+	})
+
+	tokens, err := issueSessionTokens(ctx, user, session, record.FamilyID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		return
+	}
+
+	tokens["message"] = "Token refreshed successfully"
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+// Daftar session (device) yang masih login
+func GetOwnSessions(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	// Session dianggap aktif selama belum dicabut dan masih punya refresh token yang berlaku
+	activeRefresh := database.DB.Model(&models.RefreshToken{}).
+		Select("session_id").
+		Where("user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?", userID, time.Now())
+
+	var sessions []models.Session
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND id IN (?)", userID, activeRefresh).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+
+	currentID := ctx.GetUint("session_id")
+	response := make([]gin.H, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, gin.H{
+			"id":           session.ID,
+			"device_name":  session.DeviceName,
+			"user_agent":   session.UserAgent,
+			"ip_address":   session.IPAddress,
+			"created_at":   session.CreatedAt,
+			"last_seen_at": session.LastSeenAt,
+			"current":      session.ID == currentID,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Successfully fetched sessions",
+		"sessions": response,
+	})
+}
+
+// Logout dari satu session (device) milik user sendiri
+func RevokeOwnSession(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var session models.Session
+	if err := database.DB.Where("id = ? AND user_id = ?", ctx.Param("id"), userID).First(&session).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	if err := database.RevokeSession(session.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke session"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":   "Session revoked successfully",
+		"sessionId": session.ID,
+	})
+}
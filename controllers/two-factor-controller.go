@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// jumlah recovery code yang dibuat saat 2FA diaktifkan
+const recoveryCodeCount = 10
+
+// Mulai enrollment: buat secret baru dan kembalikan URI otpauth untuk QR code.
+// 2FA belum aktif sampai kode pertama dikonfirmasi lewat EnableTwoFactor.
+func SetupTwoFactor(ctx *gin.Context) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := utils.NewTOTPSecret()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate secret"})
+		return
+	}
+	if err := database.DB.Model(&user).Updates(map[string]interface{}{
+		"totp_secret":    secret,
+		"totp_last_step": 0,
+	}).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start two-factor setup"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "Scan the QR code with your authenticator app, then confirm with a code",
+		"secret":      secret,
+		"otpauth_uri": utils.TOTPURI(config.AuthConfig.TOTPIssuer, user.Email, secret),
+	})
+}
+
+// Konfirmasi enrollment dengan kode dari authenticator; recovery code
+// hanya ditampilkan sekali di respons ini
+func EnableTwoFactor(ctx *gin.Context) {
+	var input models.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+	if user.TOTPSecret == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Start two-factor setup first"})
+		return
+	}
+	if !checkTOTPCode(user, input.Code) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("two_factor_enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable two-factor authentication"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled, store these recovery codes somewhere safe",
+		"recovery_codes": codes,
+	})
+}
+
+// Nonaktifkan 2FA; butuh password (akun lokal) dan kode TOTP atau recovery code
+func DisableTwoFactor(ctx *gin.Context) {
+	var input models.DisableTwoFactorInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if user.Password != "" && !utils.CheckPassword(user.Password, input.Password) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Wrong password"})
+		return
+	}
+	if !checkSecondFactor(user, input.Code, input.RecoveryCode) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totp_secret":           "",
+			"totp_last_step":        0,
+			"two_factor_enabled_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable two-factor authentication"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// Buat ulang recovery code (yang lama tidak berlaku lagi)
+func RegenerateRecoveryCodes(ctx *gin.Context) {
+	var input models.TwoFactorCodeInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Two-factor authentication is not enabled"})
+		return
+	}
+	if !checkTOTPCode(user, input.Code) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate recovery codes"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated",
+		"recovery_codes": codes,
+	})
+}
+
+// Langkah kedua login: tukar challenge token + kode 2FA dengan access/refresh token
+func VerifyTwoFactorLogin(ctx *gin.Context) {
+	var input models.TwoFactorLoginInput
+	if err := ctx.ShouldBind(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Login lewat Google mengirim challenge di cookie, lalu diarahkan ke /loggedin
+	fromCookie := false
+	if input.ChallengeToken == "" {
+		input.ChallengeToken, _ = ctx.Cookie(utils.TwoFactorChallengeCookie)
+		fromCookie = input.ChallengeToken != ""
+	}
+	if input.ChallengeToken == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "challenge_token is required"})
+		return
+	}
+
+	claims, err := utils.ValidateChallengeJWT(input.ChallengeToken)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+	if revoked, err := database.IsTokenRevoked(claims.ID, claims.UserID, 0, claims.IssuedAt.Time); err != nil || revoked {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, claims.UserID).Error; err != nil || user.TwoFactorEnabledAt == nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired challenge, please log in again"})
+		return
+	}
+
+	if !checkLoginAllowed(ctx, &user, "") {
+		return
+	}
+	// Kode salah ikut dihitung ke lockout akun, sama seperti password salah
+	if !checkSecondFactor(user, input.Code, input.RecoveryCode) {
+		recordLoginFailure(ctx, &user, "")
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authentication code"})
+		return
+	}
+	resetLoginFailures(user)
+
+	// Challenge hanya bisa dipakai sekali
+	database.RevokeToken(claims.ID, user.ID, claims.ExpiresAt.Time)
+
+	mergeGuestCart(ctx, user.ID)
+
+	tokens, err := issueAuthTokens(ctx, user, input.DeviceName, true)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		return
+	}
+
+	if fromCookie {
+		ctx.SetCookie(utils.TwoFactorChallengeCookie, "", -1, utils.RefreshTokenCookiePath, "", false, true)
+		ctx.Redirect(http.StatusFound, "/loggedin")
+		return
+	}
+
+	tokens["message"] = "Login successful"
+	ctx.JSON(http.StatusOK, tokens)
+}
+
+func respondTwoFactorChallenge(ctx *gin.Context, user models.User) {
+	challenge, err := utils.GenerateChallengeJWT(user.ID, config.AuthConfig.TwoFactorChallengeTTL)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create login challenge"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":             "Two-factor authentication required",
+		"two_factor_required": true,
+		"challenge_token":     challenge,
+		"expires_in":          int(config.AuthConfig.TwoFactorChallengeTTL.Seconds()),
+	})
+}
+
+func currentUser(ctx *gin.Context) (models.User, bool) {
+	var user models.User
+
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return user, false
+	}
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return user, false
+	}
+	return user, true
+}
+
+func checkSecondFactor(user models.User, code, recoveryCode string) bool {
+	if code != "" {
+		return checkTOTPCode(user, code)
+	}
+	if recoveryCode != "" {
+		return useRecoveryCode(user.ID, recoveryCode)
+	}
+	return false
+}
+
+// Kode TOTP valid dan belum pernah dipakai (time step harus lebih baru dari yang terakhir)
+func checkTOTPCode(user models.User, code string) bool {
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false
+	}
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", user.ID, step).
+		Update("totp_last_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func useRecoveryCode(userID uint, code string) bool {
+	now := time.Now()
+	result := database.DB.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(utils.NormalizeRecoveryCode(code))).
+		Limit(1).
+		Update("used_at", &now)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.NewRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(utils.NormalizeRecoveryCode(code)),
+		})
+	}
+	return codes, tx.Create(&records).Error
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,7 +13,12 @@ import (
 func GetAllUsers(ctx *gin.Context) {
 	var users []models.User
 
-	if err := database.DB.Preload("Cart").Find(&users).Error; err != nil {
+	query := database.DB
+	if wantsTrashed(ctx, models.PermUsersRead) {
+		query = query.Unscoped().Where("deleted_at IS NOT NULL")
+	}
+
+	if err := query.Preload("Cart").Find(&users).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch users",
 		})
@@ -35,6 +41,12 @@ func GetUserDetail(ctx *gin.Context) {
 		return
 	}
 
+	// Data user lain hanya untuk staff dengan permission users:read
+	if ownID, err := getIDFromContext(ctx); err != nil || (uint64(ownID) != id && !hasPermission(ctx, models.PermUsersRead)) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
 	if err := database.DB.Preload("Orders").Preload("Cart").First(&user, id).Error; err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -87,11 +99,8 @@ func DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	// Prevent deleting root admin (optional safety)
-	if user.Admin {
-		ctx.JSON(http.StatusForbidden, gin.H{
-			"error": "Cannot delete admin account",
-		})
+	// Staff tidak boleh menghapus akun yang punya permission melebihi miliknya
+	if !ensureCanManageUser(ctx, user.ID) {
 		return
 	}
 
@@ -107,6 +116,80 @@ func DeleteUser(ctx *gin.Context) {
 	})
 }
 
+func RestoreUser(ctx *gin.Context) {
+	var user models.User
+	if err := database.DB.Unscoped().Where("deleted_at IS NOT NULL").First(&user, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Deleted user not found"})
+		return
+	}
+	if !ensureCanManageUser(ctx, user.ID) {
+		return
+	}
+
+	if err := database.DB.Unscoped().Model(&user).Update("deleted_at", nil).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore user"})
+		return
+	}
+
+	database.DB.First(&user, user.ID)
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User restored successfully",
+		"user":    user,
+	})
+}
+
+// Admin: cabut semua access/refresh token user, user harus login ulang di semua device
+func RevokeUserTokens(ctx *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !ensureCanManageUser(ctx, user.ID) {
+		return
+	}
+
+	if err := database.RevokeAllUserTokens(user.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke user tokens"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "All tokens for user revoked successfully",
+		"user_id": user.ID,
+	})
+}
+
+// Admin membuka kunci akun sebelum masa lockout berakhir
+func UnlockUser(ctx *gin.Context) {
+	var user models.User
+	if err := database.DB.First(&user, ctx.Param("id")).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	if !ensureCanManageUser(ctx, user.ID) {
+		return
+	}
+
+	if err := database.DB.Model(&user).Update("locked_until", nil).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unlock user"})
+		return
+	}
+	resetLoginFailures(user)
+
+	var actorID *uint
+	if id, err := getIDFromContext(ctx); err == nil {
+		actorID = &id
+	}
+	writeAuditLog(ctx, models.AuditAccountUnlocked, &user.ID, actorID, "unlocked by admin")
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "User unlocked successfully",
+		"user_id": user.ID,
+	})
+}
+
 func UpdateProfile(ctx *gin.Context) {
 	userID, err := getIDFromContext(ctx)
 	if err != nil {
@@ -130,11 +213,21 @@ func UpdateProfile(ctx *gin.Context) {
 	if input.Username != "" {
 		user.Username = input.Username
 	}
-	if input.Email != "" {
+	// Email baru harus diverifikasi ulang
+	emailChanged := input.Email != "" && input.Email != user.Email
+	if emailChanged {
 		user.Email = input.Email
+		user.EmailVerifiedAt = nil
+	}
+
+	if err := database.DB.Save(&user).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
+		return
+	}
+
+	if emailChanged {
+		go sendVerificationEmail(user)
 	}
-	
-	database.DB.Save(&user)
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Profile updated successfully", "user": user})
 }
@@ -161,21 +254,35 @@ func UpdateUser(ctx *gin.Context) {
         ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
         return
     }
+
+    // Staff tidak boleh mengubah akun yang punya permission melebihi miliknya
+    // (mis. support mengganti email super admin lalu reset password)
+    if !ensureCanManageUser(ctx, user.ID) {
+        return
+    }
     
     // 4. Update Field
     if input.Username != "" {
         user.Username = input.Username
     }
-    if input.Email != "" {
+    // Email baru harus diverifikasi ulang dan semua session lama dicabut
+    emailChanged := input.Email != "" && input.Email != user.Email
+    if emailChanged {
         user.Email = input.Email
+        user.EmailVerifiedAt = nil
     }
-    
-    // Perubahan Hak Akses Admin (Hanya bisa diubah oleh Admin)
-    if input.Admin != nil {
-        user.Admin = *input.Admin 
+
+    if err := database.DB.Save(&user).Error; err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+        return
     }
 
-    database.DB.Save(&user)
+    if emailChanged {
+        if err := database.RevokeAllUserTokens(user.ID); err != nil {
+            fmt.Println("Failed to revoke sessions after email change:", err)
+        }
+        go sendVerificationEmail(user)
+    }
 
     ctx.JSON(http.StatusOK, gin.H{"message": "User updated successfully (Admin)", "user": user})
 }
\ No newline at end of file
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/notifications"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+func GetOwnWishlist(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var wishlist models.Wishlist
+	if err := database.DB.Preload("Items.Product").Where("user_id = ?", userID).First(&wishlist).Error; err != nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message":  "Wishlist is empty",
+			"wishlist": models.Wishlist{UserID: userID, Items: []models.WishlistItem{}},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":  "Wishlist retrieved successfully",
+		"wishlist": wishlist,
+	})
+}
+
+func AddProductToWishlist(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input models.AddWishlistItemInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var product models.Product
+	if err := database.DB.First(&product, input.ProductID).Error; err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		return
+	}
+
+	var wishlist models.Wishlist
+	if err := database.DB.Where("user_id = ?", userID).First(&wishlist).Error; err != nil {
+		wishlist = models.Wishlist{UserID: userID}
+		if err := database.DB.Create(&wishlist).Error; err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create wishlist"})
+			return
+		}
+	}
+
+	var item models.WishlistItem
+	if err := database.DB.Where("wishlist_id = ? AND product_id = ?", wishlist.ID, product.ID).First(&item).Error; err == nil {
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": "Product already in wishlist",
+			"item":    item,
+		})
+		return
+	}
+
+	item = models.WishlistItem{
+		WishlistID: wishlist.ID,
+		ProductID:  product.ID,
+		PriceAtAdd: product.Price,
+	}
+	if err := database.DB.Create(&item).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product to wishlist"})
+		return
+	}
+
+	item.Product = product
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "Product added to wishlist successfully",
+		"item":    item,
+	})
+}
+
+func RemoveWishlistItem(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	item, err := findOwnWishlistItem(userID, ctx.Param("itemId"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Wishlist item not found"})
+		return
+	}
+
+	if err := database.DB.Delete(&item).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove item from wishlist"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Item removed from wishlist successfully",
+		"itemId":  item.ID,
+	})
+}
+
+// Pindahkan item wishlist ke cart memakai aturan yang sama dengan AddProductToCart
+func MoveWishlistItemToCart(ctx *gin.Context) {
+	userID, err := getIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var input models.MoveWishlistItemToCartInput
+	if err := ctx.ShouldBindJSON(&input); err != nil && ctx.Request.ContentLength > 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	quantity := int(input.Quantity)
+	if quantity == 0 {
+		quantity = 1
+	}
+
+	item, err := findOwnWishlistItem(userID, ctx.Param("itemId"))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Wishlist item not found"})
+		return
+	}
+
+	// Tambah ke cart dan hapus dari wishlist sekaligus, supaya item tidak
+	// tertinggal di keduanya jika salah satu gagal
+	var cart models.Cart
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if cart, err = addProductToUserCart(tx, userID, item.ProductID, quantity); err != nil {
+			return err
+		}
+		return tx.Delete(&item).Error
+	})
+	if err != nil {
+		respondCartError(ctx, err, "Failed to move item to cart")
+		return
+	}
+
+	var updatedCart models.Cart
+	if err := database.DB.Preload("Items.Product").First(&updatedCart, cart.ID).Error; err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load cart"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "Item moved to cart successfully",
+		"cart":    updatedCart,
+	})
+}
+
+func findOwnWishlistItem(userID uint, itemIDParam string) (models.WishlistItem, error) {
+	var item models.WishlistItem
+
+	itemID, err := strconv.ParseUint(itemIDParam, 10, 64)
+	if err != nil {
+		return item, err
+	}
+
+	err = database.DB.
+		Joins("JOIN wishlists ON wishlists.id = wishlist_items.wishlist_id").
+		Where("wishlist_items.id = ? AND wishlists.user_id = ?", itemID, userID).
+		First(&item).Error
+	return item, err
+}
+
+// Dipanggil setelah UpdateProduct: kirim notifikasi ke semua user yang
+// menyimpan produk ini jika harga turun atau stok kembali tersedia.
+func notifyWishlistWatchers(before, after models.Product) {
+	priceDropped := after.Price < before.Price
+	backInStock := before.StockQuantity <= 0 && after.StockQuantity > 0
+	if !priceDropped && !backInStock {
+		return
+	}
+
+	var watchers []struct {
+		UserID uint
+		Email  string
+	}
+	err := database.DB.Table("wishlist_items").
+		Select("wishlists.user_id, users.email").
+		Joins("JOIN wishlists ON wishlists.id = wishlist_items.wishlist_id").
+		Joins("JOIN users ON users.id = wishlists.user_id").
+		Where("wishlist_items.product_id = ?", after.ID).
+		Scan(&watchers).Error
+	if err != nil {
+		fmt.Println("Failed to load wishlist watchers:", err)
+		return
+	}
+
+	for _, w := range watchers {
+		data := map[string]interface{}{
+			"product_id":   after.ID,
+			"product_name": after.Name,
+			"product_slug": after.Slug,
+		}
+		if priceDropped {
+			data["old_price"] = before.Price
+			data["new_price"] = after.Price
+			notifications.Send(notifications.Notification{
+				UserID:  w.UserID,
+				Email:   w.Email,
+				Type:    notifications.TypePriceDrop,
+				Subject: "Price drop on " + after.Name,
+				Message: fmt.Sprintf("%s is now %d (was %d).", after.Name, after.Price, before.Price),
+				Data:    data,
+			})
+		}
+		if backInStock {
+			notifications.Send(notifications.Notification{
+				UserID:  w.UserID,
+				Email:   w.Email,
+				Type:    notifications.TypeBackInStock,
+				Subject: after.Name + " is back in stock",
+				Message: fmt.Sprintf("%s is available again.", after.Name),
+				Data:    data,
+			})
+		}
+	}
+}
@@ -21,7 +21,8 @@ func ConnectDB() {
         cfg.DBUser, cfg.DBPass, cfg.DBHost, cfg.DBPort, cfg.DBName,
     )
 
-    db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+    // TranslateError: duplicate key dari MySQL menjadi gorm.ErrDuplicatedKey
+    db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
     if err != nil {
         log.Fatal("Failed to connect to database:", err)
     }
@@ -33,10 +34,29 @@ func ConnectDB() {
 		&models.User{},
 		&models.Category{},
 		&models.Product{},
+		&models.ProductSlugRedirect{},
 		&models.Cart{},
 		&models.CartItem{},
 		&models.Order{},
 		&models.OrderItem{},
+		&models.Review{},
+		&models.Wishlist{},
+		&models.WishlistItem{},
+		&models.ProductRelation{},
+		&models.Attribute{},
+		&models.CategoryAttribute{},
+		&models.AttributeValue{},
+		&models.AbandonedCartEvent{},
+		&models.IdempotencyKey{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
+		&models.LoginThrottle{},
+		&models.AuditLog{},
+		&models.Permission{},
+		&models.Role{},
 	)
 	if err != nil {
         log.Fatal("Migration failed:", err)
@@ -44,29 +64,47 @@ func ConnectDB() {
 	DB.Migrator().CreateConstraint(&models.CartItem{}, "UQ_cart_product")
 	DB.Exec("ALTER TABLE cart_items ADD CONSTRAINT uq_cart_product UNIQUE(cart_id, product_id);")
 
-
+	backfillProductSlugs()
+	backfillOrderItemSnapshots()
+	seedRoles()
+	backfillAdminRoles()
 	seedAdmin()
 }
 
 func seedAdmin() {
 	cfg := config.AdminConfig
 
-	var user models.User
-	if err := DB.Where("admin = ?", true).First(&user).Error; err != nil {
-		// Only create if not exist
-		hashed, _ := utils.HashPassword(cfg.AdminPass) // default password
-		admin := models.User{
-			Username:  cfg.AdminUser,
-			Email:     cfg.AdminEmail,
-			Password:  hashed,
-			Admin:     *utils.BoolPtr(true),
-			Provider:  "local",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		DB.Create(&admin)
-		fmt.Println("✅ Root admin account created: "+ admin.Email)
-	} else {
+	// Root admin = user dengan role super_admin
+	var count int64
+	DB.Model(&models.User{}).
+		Joins("JOIN user_roles ON user_roles.user_id = users.id").
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("roles.name = ?", models.RoleSuperAdmin).
+		Count(&count)
+	if count > 0 {
 		fmt.Println("ℹ️ Root admin account already exists")
+		return
+	}
+
+	var superAdmin models.Role
+	if err := DB.Where("name = ?", models.RoleSuperAdmin).First(&superAdmin).Error; err != nil {
+		fmt.Println("Failed to seed admin, role not found:", err)
+		return
 	}
-}
\ No newline at end of file
+
+	hashed, _ := utils.HashPassword(cfg.AdminPass) // default password
+	admin := models.User{
+		Username:  cfg.AdminUser,
+		Email:     cfg.AdminEmail,
+		Password:  hashed,
+		Provider:  "local",
+		Roles:     []models.Role{superAdmin},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := DB.Create(&admin).Error; err != nil {
+		fmt.Println("Failed to create root admin:", err)
+		return
+	}
+	fmt.Println("✅ Root admin account created: "+ admin.Email)
+}
@@ -0,0 +1,16 @@
+package database
+
+import "fmt"
+
+// Isi snapshot nama/SKU produk untuk order item lama yang dibuat sebelum kolom
+// snapshot ada. Best-effort: nama/SKU diambil dari produk saat ini, bukan saat
+// order dibuat. Order item baru sudah diisi oleh OrderItem.BeforeCreate.
+func backfillOrderItemSnapshots() {
+	err := DB.Exec(`UPDATE order_items
+		JOIN products ON products.id = order_items.product_id
+		SET order_items.product_name = products.name, order_items.product_sku = COALESCE(products.sku, '')
+		WHERE order_items.product_name IS NULL OR order_items.product_name = ''`).Error
+	if err != nil {
+		fmt.Println("Failed to backfill order item snapshots:", err)
+	}
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"gorm.io/gorm"
+)
+
+// PurgeSoftDeleted menghapus permanen product, category dan user yang sudah
+// berada di trash lebih lama dari retention. Product yang masih dipakai di
+// order_items dan user yang masih punya order tidak dihapus supaya riwayat
+// order dan invoice tetap utuh. Data turunan (cart, wishlist, relasi produk,
+// redirect slug) dihapus lebih dulu dalam transaksi yang sama.
+func PurgeSoftDeleted(retention time.Duration) (map[string]int64, error) {
+	cutoff := time.Now().Add(-retention)
+	purged := make(map[string]int64)
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var productIDs []uint
+		if err := tx.Unscoped().Model(&models.Product{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Where("id NOT IN (?)", tx.Table("order_items").Select("DISTINCT product_id").Where("product_id IS NOT NULL")).
+			Pluck("id", &productIDs).Error; err != nil {
+			return err
+		}
+		if len(productIDs) > 0 {
+			if err := tx.Where("product_id IN ?", productIDs).Delete(&models.CartItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("product_id IN ?", productIDs).Delete(&models.WishlistItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("product_id IN ? OR related_product_id IN ?", productIDs, productIDs).
+				Delete(&models.ProductRelation{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("product_id IN ?", productIDs).Delete(&models.ProductSlugRedirect{}).Error; err != nil {
+				return err
+			}
+			result := tx.Unscoped().Where("id IN ?", productIDs).Delete(&models.Product{})
+			if result.Error != nil {
+				return result.Error
+			}
+			purged["products"] = result.RowsAffected
+		}
+
+		result := tx.Unscoped().
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Delete(&models.Category{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged["categories"] = result.RowsAffected
+
+		var userIDs []uint
+		if err := tx.Unscoped().Model(&models.User{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+			Where("id NOT IN (?)", tx.Table("orders").Select("DISTINCT user_id").Where("user_id IS NOT NULL")).
+			Pluck("id", &userIDs).Error; err != nil {
+			return err
+		}
+		if len(userIDs) > 0 {
+			// Register selalu membuat cart, jadi cart harus dihapus sebelum user
+			userCarts := tx.Model(&models.Cart{}).Select("id").Where("user_id IN ?", userIDs)
+			if err := tx.Where("cart_id IN (?)", userCarts).Delete(&models.CartItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id IN ?", userIDs).Delete(&models.Cart{}).Error; err != nil {
+				return err
+			}
+			userWishlists := tx.Model(&models.Wishlist{}).Select("id").Where("user_id IN ?", userIDs)
+			if err := tx.Where("wishlist_id IN (?)", userWishlists).Delete(&models.WishlistItem{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("user_id IN ?", userIDs).Delete(&models.Wishlist{}).Error; err != nil {
+				return err
+			}
+			result := tx.Unscoped().Where("id IN ?", userIDs).Delete(&models.User{})
+			if result.Error != nil {
+				return result.Error
+			}
+			purged["users"] = result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return map[string]int64{}, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"log"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokeToken adds an access token's jti to the revocation list until it expires
+func RevokeToken(jti string, userID uint, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	return DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// last_seen_at session diperbarui paling sering sekali per interval ini
+const sessionTouchInterval = time.Minute
+
+// Hasil query gabungan IsTokenRevoked
+type tokenState struct {
+	TokensValidAfter *time.Time
+	SessionID        *uint
+	SessionRevokedAt *time.Time
+	SessionLastSeen  *time.Time
+	JTIRevoked       bool
+}
+
+// IsTokenRevoked reports whether an access token was revoked, either by its
+// jti, by revoking its session, or because all tokens of the user issued
+// before a point in time were revoked. User, session and revocation list are
+// read in one query. Active sessions get their last-seen time refreshed in
+// the background.
+func IsTokenRevoked(jti string, userID, sessionID uint, issuedAt time.Time) (bool, error) {
+	var state tokenState
+	result := DB.Model(&models.User{}).
+		Select("users.tokens_valid_after, sessions.id AS session_id, sessions.revoked_at AS session_revoked_at, "+
+			"sessions.last_seen_at AS session_last_seen, revoked_tokens.id IS NOT NULL AS jti_revoked").
+		Joins("LEFT JOIN sessions ON sessions.id = ? AND sessions.user_id = users.id", sessionID).
+		Joins("LEFT JOIN revoked_tokens ON revoked_tokens.jti = ?", jti).
+		Where("users.id = ?", userID).
+		Limit(1).
+		Scan(&state)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	// user dihapus: token-nya tidak berlaku lagi
+	if result.RowsAffected == 0 {
+		return true, nil
+	}
+
+	if jti != "" && state.JTIRevoked {
+		return true, nil
+	}
+	if sessionID != 0 {
+		// session tidak ada atau milik user lain
+		if state.SessionID == nil || state.SessionRevokedAt != nil {
+			return true, nil
+		}
+		if state.SessionLastSeen == nil || time.Since(*state.SessionLastSeen) > sessionTouchInterval {
+			go touchSession(sessionID)
+		}
+	}
+	// iat hanya presisi detik
+	if state.TokensValidAfter != nil && issuedAt.Before(state.TokensValidAfter.Truncate(time.Second)) {
+		return true, nil
+	}
+	return false, nil
+}
+
+// touchSession memperbarui last_seen_at; kondisi pada WHERE mencegah request
+// paralel menulis ulang baris yang sama
+func touchSession(sessionID uint) {
+	now := time.Now()
+	err := DB.Model(&models.Session{}).
+		Where("id = ? AND last_seen_at < ?", sessionID, now.Add(-sessionTouchInterval)).
+		Update("last_seen_at", now).Error
+	if err != nil {
+		log.Println("Failed to update session last_seen_at:", err)
+	}
+}
+
+// RevokeAllUserTokens invalidates every access and refresh token issued to the user so far
+func RevokeAllUserTokens(userID uint) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		return RevokeAllUserTokensTx(tx, userID)
+	})
+}
+
+// RevokeAllUserTokensTx is RevokeAllUserTokens inside the caller's transaction
+func RevokeAllUserTokensTx(tx *gorm.DB, userID uint) error {
+	now := time.Now()
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("tokens_valid_after", now).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error
+}
+
+// ExpireAccessTokens invalidates the user's current access tokens but keeps
+// their sessions, so clients pick up changed claims (e.g. permissions) on
+// the next refresh
+func ExpireAccessTokens(userID uint) error {
+	return DB.Model(&models.User{}).Where("id = ?", userID).Update("tokens_valid_after", time.Now()).Error
+}
+
+// RevokeRefreshTokenFamily revokes all refresh tokens created from the same
+// login together with the session they belong to
+func RevokeRefreshTokenFamily(familyID string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("id IN (?) AND revoked_at IS NULL", tx.Model(&models.RefreshToken{}).
+				Select("session_id").
+				Where("family_id = ?", familyID)).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", familyID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// RevokeSession logs out a single session: its access tokens are rejected
+// by AuthMiddleware and its refresh tokens can no longer be used
+func RevokeSession(sessionID uint) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.Session{}).
+			Where("id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error
+	})
+}
+
+// PurgeExpiredTokens removes revoked access tokens and refresh tokens that
+// have expired and no longer need to be remembered
+func PurgeExpiredTokens() error {
+	now := time.Now()
+	if err := DB.Where("expires_at < ?", now).Delete(&models.RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return DB.Where("expires_at < ?", now).Delete(&models.RefreshToken{}).Error
+}
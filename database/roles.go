@@ -0,0 +1,108 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var defaultRoleDescriptions = map[string]string{
+	models.RoleSuperAdmin:      "Full access, including role management",
+	models.RoleCatalogManager:  "Manages products, categories and attributes",
+	models.RoleOrderFulfilment: "Views and processes orders",
+	models.RoleSupport:         "Helps customers with their accounts, orders and reviews",
+}
+
+// seedRoles creates missing permissions and built-in roles. super_admin is
+// re-synced on every start so it also gets permissions added later; other
+// built-in roles keep whatever an operator changed in the DB.
+func seedRoles() {
+	for name, description := range models.AllPermissions {
+		DB.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoUpdates: clause.AssignmentColumns([]string{"description"}),
+		}).Create(&models.Permission{Name: name, Description: description})
+	}
+
+	var permissions []models.Permission
+	DB.Find(&permissions)
+	byName := map[string]models.Permission{}
+	for _, permission := range permissions {
+		byName[permission.Name] = permission
+	}
+
+	for name, description := range defaultRoleDescriptions {
+		var role models.Role
+		err := DB.Where("name = ?", name).First(&role).Error
+		created := err == gorm.ErrRecordNotFound
+		if created {
+			role = models.Role{Name: name, Description: description}
+			if err := DB.Create(&role).Error; err != nil {
+				fmt.Println("Failed to create role", name+":", err)
+				continue
+			}
+		} else if err != nil {
+			continue
+		}
+
+		if name == models.RoleSuperAdmin {
+			DB.Model(&role).Association("Permissions").Replace(permissions)
+		} else if created {
+			var rolePermissions []models.Permission
+			for _, permission := range models.DefaultRolePermissions[name] {
+				rolePermissions = append(rolePermissions, byName[permission])
+			}
+			DB.Model(&role).Association("Permissions").Append(rolePermissions)
+		}
+	}
+}
+
+// Admin lama (kolom users.admin sebelum ada role) otomatis menjadi
+// super_admin, lalu kolom admin dihapus
+func backfillAdminRoles() {
+	if !DB.Migrator().HasColumn(&models.User{}, "admin") {
+		return
+	}
+
+	var superAdmin models.Role
+	if err := DB.Where("name = ?", models.RoleSuperAdmin).First(&superAdmin).Error; err != nil {
+		return
+	}
+
+	withRoles := DB.Table("user_roles").Select("user_id")
+	var admins []models.User
+	if err := DB.Where("admin = ? AND id NOT IN (?)", true, withRoles).Find(&admins).Error; err != nil {
+		fmt.Println("Failed to fetch legacy admins:", err)
+		return
+	}
+	for _, admin := range admins {
+		if err := DB.Model(&admin).Association("Roles").Append(&superAdmin); err != nil {
+			fmt.Println("Failed to assign role to legacy admin", admin.ID, ":", err)
+			return
+		}
+	}
+	if len(admins) > 0 {
+		fmt.Printf("ℹ️ Assigned %s role to %d existing admin account(s)\n", models.RoleSuperAdmin, len(admins))
+	}
+
+	if err := DB.Migrator().DropColumn(&models.User{}, "admin"); err != nil {
+		fmt.Println("Failed to drop users.admin column:", err)
+	}
+}
+
+// UserPermissions returns the sorted, de-duplicated permissions granted by
+// all roles of the user
+func UserPermissions(userID uint) ([]string, error) {
+	var names []string
+	err := DB.Model(&models.Permission{}).
+		Distinct("permissions.name").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN user_roles ON user_roles.role_id = role_permissions.role_id").
+		Where("user_roles.user_id = ?", userID).
+		Pluck("permissions.name", &names).Error
+	sort.Strings(names)
+	return names, err
+}
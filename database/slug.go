@@ -0,0 +1,64 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"gorm.io/gorm"
+)
+
+// UniqueProductSlug builds a slug from name that is not used by another product,
+// either as its current slug or as an old (redirect) slug. Collisions get a
+// numeric suffix: "kaos-polos", "kaos-polos-2", "kaos-polos-3", ...
+// Soft-deleted products keep their slug so they can still be restored.
+func UniqueProductSlug(tx *gorm.DB, name string, productID uint) (string, error) {
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "product"
+	}
+
+	for n := 1; ; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(&models.Product{}).
+			Where("slug = ? AND id <> ?", candidate, productID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count > 0 {
+			continue
+		}
+
+		if err := tx.Model(&models.ProductSlugRedirect{}).
+			Where("slug = ? AND product_id <> ?", candidate, productID).
+			Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+}
+
+// Isi slug untuk produk lama yang dibuat sebelum kolom slug ada
+func backfillProductSlugs() {
+	var products []models.Product
+	if err := DB.Where("slug IS NULL OR slug = ''").Find(&products).Error; err != nil {
+		fmt.Println("Failed to load products for slug backfill:", err)
+		return
+	}
+
+	for _, product := range products {
+		slug, err := UniqueProductSlug(DB, product.Name, product.ID)
+		if err != nil {
+			fmt.Println("Failed to generate slug for product", product.ID, err)
+			continue
+		}
+		DB.Model(&product).Update("slug", slug)
+	}
+}
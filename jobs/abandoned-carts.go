@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/notifications"
+)
+
+// DetectAbandonedCarts mencatat cart user yang itemnya tidak disentuh selama
+// AbandonedCartAfter, mengirim reminder, lalu menandai event yang sudah
+// berujung order (konversi) dalam AbandonedCartConversionWindow.
+func DetectAbandonedCarts() error {
+	if err := trackAbandonedCartConversions(); err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-config.JobConfig.AbandonedCartAfter)
+
+	// Guest cart dilewati karena tidak ada alamat email untuk reminder
+	var carts []struct {
+		CartID         uint
+		UserID         uint
+		Email          string
+		LastActivityAt time.Time
+		ItemCount      int
+		CartTotal      int64
+	}
+	err := database.DB.Table("carts").
+		Select("carts.id AS cart_id, carts.user_id, users.email, "+
+			"MAX(COALESCE(cart_items.updated_at, carts.updated_at)) AS last_activity_at, "+
+			"SUM(cart_items.quantity) AS item_count, "+
+			"SUM(cart_items.quantity * products.price) AS cart_total").
+		Joins("JOIN cart_items ON cart_items.cart_id = carts.id AND cart_items.saved_for_later = ?", false).
+		Joins("JOIN products ON products.id = cart_items.product_id").
+		Joins("JOIN users ON users.id = carts.user_id AND users.deleted_at IS NULL").
+		Where("carts.user_id IS NOT NULL").
+		Group("carts.id, carts.user_id, users.email").
+		Having("MAX(COALESCE(cart_items.updated_at, carts.updated_at)) < ?", cutoff).
+		Scan(&carts).Error
+	if err != nil {
+		return err
+	}
+
+	for _, c := range carts {
+		// Satu event per periode abandoned; cart yang disentuh lagi lalu
+		// ditinggal kembali akan punya last_activity_at yang lebih baru
+		var count int64
+		if err := database.DB.Model(&models.AbandonedCartEvent{}).
+			Where("cart_id = ? AND last_activity_at >= ?", c.CartID, c.LastActivityAt).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+
+		event := models.AbandonedCartEvent{
+			CartID:         c.CartID,
+			UserID:         c.UserID,
+			LastActivityAt: c.LastActivityAt,
+			ItemCount:      c.ItemCount,
+			CartTotal:      c.CartTotal,
+		}
+		if err := database.DB.Create(&event).Error; err != nil {
+			return err
+		}
+
+		err := notifications.Default.Notify(notifications.Notification{
+			UserID:  c.UserID,
+			Email:   c.Email,
+			Type:    notifications.TypeAbandonedCart,
+			Subject: "You left something in your cart",
+			Message: fmt.Sprintf("You still have %d item(s) worth %d waiting in your cart.", c.ItemCount, c.CartTotal),
+			Data: map[string]interface{}{
+				"cart_id":    c.CartID,
+				"event_id":   event.ID,
+				"item_count": c.ItemCount,
+				"cart_total": c.CartTotal,
+			},
+		})
+		if err != nil {
+			// event tetap tercatat tanpa reminder_sent_at
+			fmt.Println("Failed to send abandoned cart reminder:", err)
+			continue
+		}
+
+		now := time.Now()
+		database.DB.Model(&event).Update("reminder_sent_at", &now)
+	}
+
+	return nil
+}
+
+// Event dianggap konversi jika user membuat order (yang tidak dibatalkan)
+// setelah event tercatat dan masih di dalam conversion window
+func trackAbandonedCartConversions() error {
+	window := config.JobConfig.AbandonedCartConversionWindow
+
+	var events []models.AbandonedCartEvent
+	if err := database.DB.
+		Where("converted_order_id IS NULL AND created_at >= ?", time.Now().Add(-window)).
+		Find(&events).Error; err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		var order models.Order
+		err := database.DB.
+			Where("user_id = ? AND status <> ? AND created_at >= ? AND created_at <= ?",
+				event.UserID, models.OrderCanceled, event.CreatedAt, event.CreatedAt.Add(window)).
+			Order("created_at ASC").
+			First(&order).Error
+		if err != nil {
+			continue
+		}
+
+		if err := database.DB.Model(&event).Updates(map[string]interface{}{
+			"converted_order_id": order.ID,
+			"converted_at":       order.CreatedAt,
+		}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
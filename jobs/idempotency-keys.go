@@ -0,0 +1,13 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+)
+
+// PurgeExpiredIdempotencyKeys menghapus response Idempotency-Key yang sudah kedaluwarsa
+func PurgeExpiredIdempotencyKeys() error {
+	return database.DB.Where("expires_at < ?", time.Now()).Delete(&models.IdempotencyKey{}).Error
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"log"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+)
+
+// Start menjalankan semua background job periodik
+func Start() {
+	every("related-products", config.JobConfig.RelatedProductsInterval, RefreshProductRelations)
+	every("abandoned-carts", config.JobConfig.AbandonedCartInterval, DetectAbandonedCarts)
+	every("idempotency-keys", config.JobConfig.IdempotencyCleanupInterval, PurgeExpiredIdempotencyKeys)
+	every("expired-tokens", config.JobConfig.TokenCleanupInterval, database.PurgeExpiredTokens)
+}
+
+// every menjalankan fn sekali saat startup lalu setiap interval di goroutine terpisah
+func every(name string, interval time.Duration, fn func() error) {
+	if interval <= 0 {
+		log.Printf("[job] %s disabled\n", name)
+		return
+	}
+
+	run := func() {
+		start := time.Now()
+		if err := fn(); err != nil {
+			log.Printf("[job] %s failed: %v\n", name, err)
+			return
+		}
+		log.Printf("[job] %s finished in %s\n", name, time.Since(start))
+	}
+
+	go func() {
+		run()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"sort"
+
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// jumlah maksimal produk terkait yang disimpan per produk
+const maxRelatedProducts = 20
+
+// RefreshProductRelations menghitung ulang tabel product_relations dari
+// statistik co-purchase (order_items) dan produk dalam category yang sama.
+func RefreshProductRelations() error {
+	var pairs []struct {
+		ProductID        uint
+		RelatedProductID uint
+		CoPurchaseCount  int
+	}
+	err := database.DB.Table("order_items AS a").
+		Select("a.product_id AS product_id, b.product_id AS related_product_id, COUNT(DISTINCT a.order_id) AS co_purchase_count").
+		Joins("JOIN order_items AS b ON b.order_id = a.order_id AND b.product_id <> a.product_id").
+		Joins("JOIN orders ON orders.id = a.order_id").
+		Where("orders.status <> ?", models.OrderCanceled).
+		Group("a.product_id, b.product_id").
+		Scan(&pairs).Error
+	if err != nil {
+		return err
+	}
+
+	var products []models.Product
+	if err := database.DB.Select("id", "category_id", "rating_average", "rating_count").
+		Where("is_active = ?", true).
+		Order("rating_count DESC, id ASC").
+		Find(&products).Error; err != nil {
+		return err
+	}
+
+	relations := make(map[uint][]models.ProductRelation)
+	seen := make(map[[2]uint]bool)
+
+	// 1. co-purchase, skor = jumlah order yang berisi kedua produk
+	for _, p := range pairs {
+		relations[p.ProductID] = append(relations[p.ProductID], models.ProductRelation{
+			ProductID:        p.ProductID,
+			RelatedProductID: p.RelatedProductID,
+			Source:           models.RelationCoPurchase,
+			CoPurchaseCount:  p.CoPurchaseCount,
+			Score:            float64(p.CoPurchaseCount),
+		})
+		seen[[2]uint{p.ProductID, p.RelatedProductID}] = true
+	}
+	for id := range relations {
+		list := relations[id]
+		sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+		if len(list) > maxRelatedProducts {
+			list = list[:maxRelatedProducts]
+		}
+		relations[id] = list
+	}
+
+	// 2. sisa slot diisi produk dari category yang sama; skor < 1 supaya
+	//    selalu di bawah hasil co-purchase
+	byCategory := make(map[uint][]models.Product)
+	for _, p := range products {
+		if len(byCategory[p.CategoryID]) < maxRelatedProducts+1 {
+			byCategory[p.CategoryID] = append(byCategory[p.CategoryID], p)
+		}
+	}
+	for _, p := range products {
+		for _, candidate := range byCategory[p.CategoryID] {
+			if len(relations[p.ID]) >= maxRelatedProducts {
+				break
+			}
+			key := [2]uint{p.ID, candidate.ID}
+			if candidate.ID == p.ID || seen[key] {
+				continue
+			}
+			seen[key] = true
+			relations[p.ID] = append(relations[p.ID], models.ProductRelation{
+				ProductID:        p.ID,
+				RelatedProductID: candidate.ID,
+				Source:           models.RelationSameCategory,
+				Score:            candidate.RatingAverage / 10,
+			})
+		}
+	}
+
+	var rows []models.ProductRelation
+	for _, list := range relations {
+		rows = append(rows, list...)
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ProductRelation{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(rows, 500).Error
+	})
+}
@@ -0,0 +1,121 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string // plain text
+}
+
+// Mailer mengirim email transaksional (reset password, verifikasi email, dll)
+type Mailer interface {
+	Send(m Message) error
+}
+
+// SMTPMailer mengirim lewat server SMTP dengan PLAIN auth
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s SMTPMailer) Send(m Message) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+
+	headers := []string{
+		"From: " + s.From,
+		"To: " + m.To,
+		"Subject: " + m.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	msg := strings.Join(headers, "\r\n") + "\r\n\r\n" + m.Body
+
+	return smtp.SendMail(s.Host+":"+s.Port, auth, s.From, []string{m.To}, []byte(msg))
+}
+
+// LogMailer hanya menulis email ke log, untuk development. Link reset
+// password ikut tercatat di log, jadi jangan dipakai di production.
+type LogMailer struct{}
+
+func (LogMailer) Send(m Message) error {
+	log.Printf("[mail] to=%s subject=%q\n%s\n", m.To, m.Subject, m.Body)
+	return nil
+}
+
+// Jumlah email yang disimpan CaptureMailer, yang lebih lama dibuang
+const captureLimit = 100
+
+// CaptureMailer menyimpan email terakhir di memory tanpa mengirimnya,
+// dipakai di test untuk membaca link yang dikirim
+type CaptureMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (c *CaptureMailer) Send(m Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, m)
+	if len(c.messages) > captureLimit {
+		c.messages = append([]Message(nil), c.messages[len(c.messages)-captureLimit:]...)
+	}
+	return nil
+}
+
+// Messages returns a copy of all captured emails
+func (c *CaptureMailer) Messages() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Last returns the most recently captured email sent to the address
+func (c *CaptureMailer) Last(to string) (Message, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if strings.EqualFold(c.messages[i].To, to) {
+			return c.messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// Default mailer yang dipakai aplikasi, diganti oleh Setup saat startup
+// atau oleh test dengan CaptureMailer
+var Default Mailer = LogMailer{}
+
+// Setup memilih Default mailer dari config ("smtp" atau "log")
+func Setup(kind string, smtpMailer SMTPMailer) {
+	switch kind {
+	case "", "smtp":
+		Default = smtpMailer
+	case "log", "capture":
+		log.Println("WARNING: MAILER=" + kind + " does not deliver email and writes password reset links to the log; use it for development only")
+		Default = LogMailer{}
+	default:
+		log.Printf("Unknown mailer %q, using smtp\n", kind)
+		Default = smtpMailer
+	}
+}
+
+// Send mengirim lewat Default mailer dan mencatat error tanpa menghentikan proses
+func Send(m Message) {
+	if err := Default.Send(m); err != nil {
+		log.Println("Failed to send email:", fmt.Sprintf("to=%s subject=%q:", m.To, m.Subject), err)
+	}
+}
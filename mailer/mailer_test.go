@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCaptureMailerLast(t *testing.T) {
+	c := &CaptureMailer{}
+	c.Send(Message{To: "a@example.com", Subject: "first"})
+	c.Send(Message{To: "b@example.com", Subject: "other"})
+	c.Send(Message{To: "A@example.com", Subject: "second"})
+
+	m, ok := c.Last("a@example.com")
+	if !ok || m.Subject != "second" {
+		t.Fatalf("Last = %+v, %v; want subject second", m, ok)
+	}
+	if _, ok := c.Last("nobody@example.com"); ok {
+		t.Fatal("Last found a message for an unknown address")
+	}
+}
+
+func TestCaptureMailerKeepsOnlyRecentMessages(t *testing.T) {
+	c := &CaptureMailer{}
+	for i := 0; i < captureLimit+10; i++ {
+		c.Send(Message{To: "a@example.com", Subject: fmt.Sprint(i)})
+	}
+
+	messages := c.Messages()
+	if len(messages) != captureLimit {
+		t.Fatalf("len(Messages()) = %d, want %d", len(messages), captureLimit)
+	}
+	if messages[0].Subject != "10" {
+		t.Fatalf("oldest kept message = %q, want %q", messages[0].Subject, "10")
+	}
+}
+
+func TestSendUsesDefault(t *testing.T) {
+	capture := &CaptureMailer{}
+	previous := Default
+	Default = capture
+	defer func() { Default = previous }()
+
+	Send(Message{To: "a@example.com", Subject: "hello"})
+	if len(capture.Messages()) != 1 {
+		t.Fatal("Send did not use the Default mailer")
+	}
+}
@@ -1,9 +1,14 @@
 package middlewares
 
 import (
+	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
 	"github.com/ASaifaji/as-gin-ecommerce/utils"
 	"github.com/gin-gonic/gin"
 )
@@ -11,40 +16,51 @@ import (
 // AuthMiddleware checks JWT from Authorization header
 func AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader("Authorization")
-		var tokenStr string
-
-		if authHeader != "" {
-			tokenStr = strings.TrimPrefix(authHeader, "Bearer ")
-		} else {
-			cookie, err := ctx.Cookie("auth_token")
-			if err != nil {
-				ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-				ctx.Abort()
-				return 
-			}
-			tokenStr = cookie
+		tokenStr := tokenFromRequest(ctx)
+		if tokenStr == "" {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			ctx.Abort()
+			return
 		}
-		
+
 		claims, err := utils.ValidateJWT(tokenStr)
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token Invalid"})
 			ctx.Abort()
+			return
+		}
+
+		if tokenRevoked(claims) {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Token Revoked"})
+			ctx.Abort()
+			return
 		}
 
-		// Put claims into Gin
-		ctx.Set("id", claims.UserID)
-		ctx.Set("email", claims.Email)
-		ctx.Set("admin", claims.Admin)
+		setClaims(ctx, claims)
+
+		ctx.Next()
+	}
+}
+
+// OptionalAuth sets the JWT claims when a valid token is present but lets
+// anonymous requests through (used by endpoints that also serve guests)
+func OptionalAuth() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if tokenStr := tokenFromRequest(ctx); tokenStr != "" {
+			if claims, err := utils.ValidateJWT(tokenStr); err == nil && !tokenRevoked(claims) {
+				setClaims(ctx, claims)
+			}
+		}
 
 		ctx.Next()
 	}
 }
 
-func AuthAdmin() gin.HandlerFunc {
+// RequirePermission allows the request only if the token carries the
+// permission (granted through the user's roles). Must run after AuthMiddleware.
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		// Make sure user is authenticated first (AuthMiddleware())
-		adminVal, exists := ctx.Get("admin")
+		permissionsVal, exists := ctx.Get("permissions")
 		if !exists {
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
 				"error": "Unauthorized",
@@ -52,14 +68,61 @@ func AuthAdmin() gin.HandlerFunc {
 			return
 		}
 
-		isAdmin, ok := adminVal.(bool)
-		if !ok || !isAdmin {
+		permissions, _ := permissionsVal.([]string)
+		if !slices.Contains(permissions, permission) {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-				"error": "Forbidden: admin access required",
+				"error": "Forbidden: missing permission " + permission,
+			})
+			return
+		}
+
+		// Jika diwajibkan, staff (user dengan role) harus login lewat 2FA
+		if config.AuthConfig.Require2FAForAdmins && !ctx.GetBool("two_factor") {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Forbidden: two-factor authentication required for admin access",
 			})
 			return
 		}
 		
 		ctx.Next()
 	}
-}
\ No newline at end of file
+}
+
+// Token dari header Authorization, atau cookie auth_token jika header kosong
+func tokenFromRequest(ctx *gin.Context) string {
+	if authHeader := ctx.GetHeader("Authorization"); authHeader != "" {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	cookie, err := ctx.Cookie(utils.AccessTokenCookie)
+	if err != nil {
+		return ""
+	}
+	return cookie
+}
+
+// Token dicabut lewat logout / logout semua sesi / admin
+func tokenRevoked(claims *utils.Claims) bool {
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+	revoked, err := database.IsTokenRevoked(claims.ID, claims.UserID, claims.SessionID, issuedAt)
+	if err != nil {
+		log.Println("Failed to check token revocation:", err)
+		return true
+	}
+	return revoked
+}
+
+// Put claims into Gin
+func setClaims(ctx *gin.Context, claims *utils.Claims) {
+	ctx.Set("id", claims.UserID)
+	ctx.Set("email", claims.Email)
+	ctx.Set("permissions", claims.Permissions)
+	ctx.Set("jti", claims.ID)
+	ctx.Set("session_id", claims.SessionID)
+	ctx.Set("two_factor", claims.TwoFactor)
+	if claims.ExpiresAt != nil {
+		ctx.Set("token_expires_at", claims.ExpiresAt.Time)
+	}
+}
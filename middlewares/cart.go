@@ -0,0 +1,43 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// guest cart cookie berlaku 30 hari
+const guestCartMaxAge = 30 * 24 * 60 * 60
+
+// GuestCart identifies anonymous shoppers by a signed cookie and puts the
+// token into the context as "guest_token". Requests already authenticated
+// by OptionalAuth() are passed through unchanged.
+func GuestCart() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if _, loggedIn := ctx.Get("id"); loggedIn {
+			ctx.Next()
+			return
+		}
+
+		secret := []byte(config.AppConfig.CartSecret)
+		if cookie, err := ctx.Cookie(utils.GuestCartCookie); err == nil {
+			if token, ok := utils.VerifyGuestToken(cookie, secret); ok {
+				ctx.Set("guest_token", token)
+				ctx.Next()
+				return
+			}
+		}
+
+		token, err := utils.NewGuestToken()
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create guest cart"})
+			return
+		}
+		ctx.SetCookie(utils.GuestCartCookie, utils.SignGuestToken(token, secret), guestCartMaxAge, "/", "", false, true)
+		ctx.Set("guest_token", token)
+
+		ctx.Next()
+	}
+}
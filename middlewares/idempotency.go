@@ -0,0 +1,147 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/gin-gonic/gin"
+)
+
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyWriter menyalin response body supaya bisa disimpan dan di-replay
+type idempotencyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency handles the Idempotency-Key header on unsafe endpoints. The
+// first request with a key stores its response; repeats with the same body
+// get the stored response replayed, repeats with a different body get 422.
+// Requests without the header are passed through. Must run after
+// AuthMiddleware (or GuestCart) because keys are scoped per user.
+func Idempotency() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if key == "" || ctx.Request.Method == http.MethodGet || ctx.Request.Method == http.MethodHead {
+			ctx.Next()
+			return
+		}
+		if len(key) > 255 {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(ctx)
+		fingerprint := requestFingerprint(ctx.Request.Method, ctx.Request.URL.Path, body)
+
+		// Key yang sudah kedaluwarsa boleh dipakai ulang
+		database.DB.Where("scope = ? AND idempotency_key = ? AND expires_at < ?", scope, key, time.Now()).
+			Delete(&models.IdempotencyKey{})
+
+		record := models.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   time.Now().Add(config.JobConfig.IdempotencyKeyTTL),
+		}
+		if err := database.DB.Create(&record).Error; err != nil {
+			// Unique index (scope, key): request dengan key ini sudah pernah masuk
+			var existing models.IdempotencyKey
+			if err := database.DB.Where("scope = ? AND idempotency_key = ?", scope, key).First(&existing).Error; err != nil {
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to store idempotency key"})
+				return
+			}
+			replayIdempotentResponse(ctx, existing, fingerprint)
+			return
+		}
+
+		writer := &idempotencyWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		// Handler panic: hapus key sebelum Recovery menulis 500, supaya retry
+		// tidak tertahan 409 sampai key kedaluwarsa
+		defer func() {
+			if r := recover(); r != nil {
+				database.DB.Delete(&record)
+				panic(r)
+			}
+		}()
+
+		ctx.Next()
+
+		// Error server tidak disimpan supaya client bisa mencoba lagi dengan key yang sama
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			database.DB.Delete(&record)
+			return
+		}
+		database.DB.Model(&record).Updates(map[string]interface{}{
+			"status_code":   status,
+			"content_type":  writer.Header().Get("Content-Type"),
+			"response_body": writer.body.Bytes(),
+		})
+	}
+}
+
+func replayIdempotentResponse(ctx *gin.Context, existing models.IdempotencyKey, fingerprint string) {
+	if existing.Fingerprint != fingerprint {
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Idempotency-Key was already used with a different request",
+		})
+		return
+	}
+	if existing.StatusCode == 0 {
+		ctx.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "A request with this Idempotency-Key is still being processed",
+		})
+		return
+	}
+
+	ctx.Header("Idempotent-Replayed", "true")
+	ctx.Data(existing.StatusCode, existing.ContentType, existing.ResponseBody)
+	ctx.Abort()
+}
+
+// Key di-scope per user (atau guest cart) supaya tidak bentrok antar client
+func idempotencyScope(ctx *gin.Context) string {
+	if id, ok := ctx.Get("id"); ok {
+		return fmt.Sprintf("user:%v", id)
+	}
+	if token := ctx.GetString("guest_token"); token != "" {
+		return "guest:" + token
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
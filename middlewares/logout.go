@@ -1,12 +1,79 @@
 package middlewares
 
 import (
+	"log"
+	"net/http"
+	"time"
 
+	"github.com/ASaifaji/as-gin-ecommerce/database"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
+	"github.com/ASaifaji/as-gin-ecommerce/utils"
 	"github.com/gin-gonic/gin"
 )
 
+// Logout revokes the current access token (by jti), its session and the
+// refresh token family of this login, then clears the auth cookies. It always succeeds so
+// clients with an already expired token can still clear their cookies.
+// The refresh token is read from the body (refresh_token) or from the cookie,
+// which browsers only send under RefreshTokenCookiePath (/api/auth/logout).
 func Logout(ctx *gin.Context) {
-	// Overwrite the cookie with an empty value and set it expired
-	ctx.SetCookie("Authorization", "", -1, "/", "localhost", false, true)
-	return
+	if tokenStr := tokenFromRequest(ctx); tokenStr != "" {
+		if claims, err := utils.ValidateJWT(tokenStr); err == nil {
+			expiresAt := time.Now().Add(utils.AccessTokenTTL)
+			if claims.ExpiresAt != nil {
+				expiresAt = claims.ExpiresAt.Time
+			}
+			if err := database.RevokeToken(claims.ID, claims.UserID, expiresAt); err != nil {
+				log.Println("Failed to revoke token:", err)
+			}
+			if claims.SessionID != 0 {
+				if err := database.RevokeSession(claims.SessionID); err != nil {
+					log.Println("Failed to revoke session:", err)
+				}
+			}
+		}
+	}
+
+	var input models.RefreshTokenInput
+	ctx.ShouldBind(&input)
+	refreshToken := input.RefreshToken
+	if refreshToken == "" {
+		refreshToken, _ = ctx.Cookie(utils.RefreshTokenCookie)
+	}
+	if refreshToken != "" {
+		var record models.RefreshToken
+		if database.DB.Where("token_hash = ?", utils.HashToken(refreshToken)).First(&record).Error == nil {
+			if err := database.RevokeRefreshTokenFamily(record.FamilyID); err != nil {
+				log.Println("Failed to revoke refresh token:", err)
+			}
+		}
+	}
+
+	clearAuthCookies(ctx)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+// LogoutAllSessions revokes every token of the current user on all devices.
+// Must run after AuthMiddleware.
+func LogoutAllSessions(ctx *gin.Context) {
+	userID, ok := ctx.Get("id")
+	id, _ := userID.(uint)
+	if !ok || id == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := database.RevokeAllUserTokens(id); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log out all sessions"})
+		return
+	}
+
+	clearAuthCookies(ctx)
+	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out from all sessions"})
+}
+
+func clearAuthCookies(ctx *gin.Context) {
+	// Overwrite the cookies with an empty value and set them expired
+	ctx.SetCookie(utils.AccessTokenCookie, "", -1, "/", "", false, true)
+	ctx.SetCookie(utils.RefreshTokenCookie, "", -1, utils.RefreshTokenCookiePath, "", false, true)
 }
@@ -0,0 +1,164 @@
+package middlewares
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Algoritma rate limit yang didukung
+const (
+	TokenBucket   = "token_bucket"   // burst sampai Limit, lalu diisi ulang Limit token per Window
+	SlidingWindow = "sliding_window" // maksimal Limit request dalam Window terakhir
+)
+
+// RateLimitRule is what a store needs to decide a single request
+type RateLimitRule struct {
+	Algorithm string
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the outcome of one RateLimitStore.Take call
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // sampai kuota penuh kembali
+	RetryAfter time.Duration // hanya diisi jika Allowed = false
+}
+
+// RateLimitStore keeps the counters behind RateLimit. Take must check and
+// consume one request atomically; a shared backend such as Redis can
+// implement it with a Lua script so limits hold across instances.
+type RateLimitStore interface {
+	Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+}
+
+// MemoryRateLimitStore keeps counters in process memory. Limits are per
+// instance, so use a shared store when running more than one server.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	entries   map[string]*rateLimitEntry
+	nextSweep time.Time
+}
+
+type rateLimitEntry struct {
+	// token bucket
+	tokens     float64
+	lastRefill time.Time
+
+	// sliding window (counter window sekarang + window sebelumnya)
+	windowStart time.Time
+	current     int
+	previous    int
+
+	expiresAt time.Time
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{entries: map[string]*rateLimitEntry{}}
+}
+
+func (s *MemoryRateLimitStore) Take(key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	entry, exists := s.entries[key]
+	if !exists {
+		entry = &rateLimitEntry{tokens: float64(rule.Limit), lastRefill: now, windowStart: now.Truncate(rule.Window)}
+		s.entries[key] = entry
+	}
+	// Entry yang tidak disentuh selama dua window sudah kembali ke kondisi awal
+	entry.expiresAt = now.Add(2 * rule.Window)
+
+	if rule.Algorithm == TokenBucket {
+		return takeTokenBucket(entry, rule, now), nil
+	}
+	return takeSlidingWindow(entry, rule, now), nil
+}
+
+// Hapus entry kedaluwarsa paling sering sekali per menit
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for key, entry := range s.entries {
+		if entry.expiresAt.Before(now) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(time.Minute)
+}
+
+func takeTokenBucket(entry *rateLimitEntry, rule RateLimitRule, now time.Time) RateLimitResult {
+	limit := float64(rule.Limit)
+	perSecond := limit / rule.Window.Seconds()
+
+	elapsed := now.Sub(entry.lastRefill).Seconds()
+	if elapsed > 0 {
+		entry.tokens = math.Min(limit, entry.tokens+elapsed*perSecond)
+		entry.lastRefill = now
+	}
+
+	result := RateLimitResult{Limit: rule.Limit}
+	if entry.tokens >= 1 {
+		entry.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsDuration((1 - entry.tokens) / perSecond)
+	}
+	result.Remaining = int(entry.tokens)
+	result.ResetAfter = secondsDuration((limit - entry.tokens) / perSecond)
+	return result
+}
+
+// Sliding window counter: jumlah request diperkirakan dari window sekarang
+// ditambah porsi window sebelumnya yang masih tercakup
+func takeSlidingWindow(entry *rateLimitEntry, rule RateLimitRule, now time.Time) RateLimitResult {
+	start := now.Truncate(rule.Window)
+	if start.After(entry.windowStart) {
+		if start.Sub(entry.windowStart) == rule.Window {
+			entry.previous = entry.current
+		} else {
+			entry.previous = 0
+		}
+		entry.current = 0
+		entry.windowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - elapsed.Seconds()/rule.Window.Seconds()
+	estimated := float64(entry.previous)*weight + float64(entry.current)
+	limit := float64(rule.Limit)
+
+	result := RateLimitResult{Limit: rule.Limit, ResetAfter: rule.Window - elapsed}
+	if estimated+1 <= limit {
+		entry.current++
+		estimated++
+		result.Allowed = true
+	} else if entry.current+1 <= rule.Limit {
+		// Tunggu sampai porsi window sebelumnya cukup mengecil
+		needWeight := (limit - float64(entry.current) - 1) / float64(entry.previous)
+		result.RetryAfter = secondsDuration((1-needWeight)*rule.Window.Seconds()) - elapsed
+	} else {
+		// Window sekarang sudah penuh, tunggu sampai window berikutnya
+		needWeight := (limit - 1) / float64(entry.current)
+		result.RetryAfter = rule.Window - elapsed + secondsDuration((1-needWeight)*rule.Window.Seconds())
+	}
+
+	result.Remaining = rule.Limit - int(math.Ceil(estimated))
+	if result.Remaining < 0 {
+		result.Remaining = 0
+	}
+	if !result.Allowed && result.RetryAfter <= 0 {
+		result.RetryAfter = time.Second
+	}
+	return result
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
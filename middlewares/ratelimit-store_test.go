@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"testing"
+	"time"
+)
+
+type rateLimitStep struct {
+	at         time.Duration // sejak awal test
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	resetAfter time.Duration
+}
+
+func runRateLimitSteps(t *testing.T, rule RateLimitRule, start time.Time, steps []rateLimitStep) {
+	t.Helper()
+	store := NewMemoryRateLimitStore()
+	for i, step := range steps {
+		got, err := store.Take("key", rule, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("step %d: unexpected error: %v", i, err)
+		}
+		want := RateLimitResult{
+			Allowed:    step.allowed,
+			Limit:      rule.Limit,
+			Remaining:  step.remaining,
+			RetryAfter: step.retryAfter,
+			ResetAfter: step.resetAfter,
+		}
+		if got != want {
+			t.Errorf("step %d (t+%s): got %+v, want %+v", i, step.at, got, want)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	// 3 token, diisi ulang 1 token per detik
+	rule := RateLimitRule{Algorithm: TokenBucket, Limit: 3, Window: 3 * time.Second}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	runRateLimitSteps(t, rule, start, []rateLimitStep{
+		{at: 0, allowed: true, remaining: 2, resetAfter: 1 * time.Second},
+		{at: 0, allowed: true, remaining: 1, resetAfter: 2 * time.Second},
+		{at: 0, allowed: true, remaining: 0, resetAfter: 3 * time.Second},
+		{at: 0, allowed: false, remaining: 0, retryAfter: 1 * time.Second, resetAfter: 3 * time.Second},
+		// 1.5 token terisi: satu request lolos, sisa 0.5 token
+		{at: 1500 * time.Millisecond, allowed: true, remaining: 0, resetAfter: 2500 * time.Millisecond},
+		{at: 1500 * time.Millisecond, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetAfter: 2500 * time.Millisecond},
+		// bucket tidak pernah melebihi Limit
+		{at: time.Minute, allowed: true, remaining: 2, resetAfter: 1 * time.Second},
+	})
+}
+
+func TestSlidingWindow(t *testing.T) {
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 2, Window: time.Minute}
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC) // awal window
+
+	runRateLimitSteps(t, rule, start, []rateLimitStep{
+		{at: 0, allowed: true, remaining: 1, resetAfter: 60 * time.Second},
+		{at: 10 * time.Second, allowed: true, remaining: 0, resetAfter: 50 * time.Second},
+		// window penuh: tunggu 40s ke window berikutnya + 30s sampai porsi window lama cukup kecil
+		{at: 20 * time.Second, allowed: false, remaining: 0, retryAfter: 70 * time.Second, resetAfter: 40 * time.Second},
+		// window kedua, 30s berjalan: 2 request lama dihitung separuh
+		{at: 90 * time.Second, allowed: true, remaining: 0, resetAfter: 30 * time.Second},
+		// window sekarang masih muat, tunggu porsi window lama habis
+		{at: 95 * time.Second, allowed: false, remaining: 0, retryAfter: 25 * time.Second, resetAfter: 25 * time.Second},
+		// window ketiga: window sebelumnya (1 request) dihitung separuh
+		{at: 150 * time.Second, allowed: true, remaining: 0, resetAfter: 30 * time.Second},
+		// lebih dari satu window kosong: hitungan mulai dari nol
+		{at: 300 * time.Second, allowed: true, remaining: 1, resetAfter: 60 * time.Second},
+	})
+}
+
+func TestMemoryRateLimitStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	rule := RateLimitRule{Algorithm: SlidingWindow, Limit: 1, Window: time.Minute}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if res, _ := store.Take("a", rule, now); !res.Allowed {
+		t.Fatal("first request for a was rejected")
+	}
+	if res, _ := store.Take("a", rule, now); res.Allowed {
+		t.Fatal("second request for a was allowed")
+	}
+	if res, _ := store.Take("b", rule, now); !res.Allowed {
+		t.Fatal("request for b was limited by a")
+	}
+}
+
+func TestMemoryRateLimitStoreSweepsIdleEntries(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	rule := RateLimitRule{Algorithm: TokenBucket, Limit: 1, Window: time.Minute}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	store.Take("idle", rule, now)
+	store.Take("other", rule, now.Add(3*time.Minute))
+
+	if _, exists := store.entries["idle"]; exists {
+		t.Fatal("idle entry was not removed after two windows")
+	}
+	if _, exists := store.entries["other"]; !exists {
+		t.Fatal("active entry was removed")
+	}
+}
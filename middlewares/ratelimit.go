@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ASaifaji/as-gin-ecommerce/config"
+	"github.com/gin-gonic/gin"
+)
+
+// Store yang dipakai policy tanpa Store sendiri
+var DefaultRateLimitStore RateLimitStore = NewMemoryRateLimitStore()
+
+// RateLimitKeyFunc returns who a request is counted against
+type RateLimitKeyFunc func(ctx *gin.Context) string
+
+// Hitung per alamat IP
+func RateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// Hitung per user yang login, IP untuk guest. Harus dipasang setelah
+// AuthMiddleware / OptionalAuth.
+func RateLimitByUser(ctx *gin.Context) string {
+	if id, exists := ctx.Get("id"); exists {
+		return fmt.Sprintf("user:%v", id)
+	}
+	return RateLimitByIP(ctx)
+}
+
+// Satu kuota bersama untuk semua pemanggil route
+func RateLimitByRoute(ctx *gin.Context) string {
+	return "route:" + ctx.FullPath()
+}
+
+// RateLimitPolicy configures one RateLimit middleware
+type RateLimitPolicy struct {
+	Name      string // prefix key di store, policy berbeda tidak berbagi counter
+	Algorithm string // TokenBucket atau SlidingWindow
+	Limit     int
+	Window    time.Duration
+	Key       RateLimitKeyFunc // default RateLimitByIP
+	PerRoute  bool             // counter terpisah untuk tiap route dalam group
+	Store     RateLimitStore   // default DefaultRateLimitStore
+}
+
+// RateLimit rejects requests over the policy with 429 and sets the
+// RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and RateLimit-Policy
+// headers on every response (plus Retry-After when rejected). If the store
+// fails the request is let through rather than taking the API down.
+func RateLimit(policy RateLimitPolicy) gin.HandlerFunc {
+	if policy.Key == nil {
+		policy.Key = RateLimitByIP
+	}
+	if policy.Algorithm == "" {
+		policy.Algorithm = SlidingWindow
+	}
+	rule := RateLimitRule{Algorithm: policy.Algorithm, Limit: policy.Limit, Window: policy.Window}
+	policyHeader := fmt.Sprintf("%d;w=%d", policy.Limit, int(policy.Window.Seconds()))
+
+	return func(ctx *gin.Context) {
+		if !config.RateLimitConfig.Enabled || policy.Limit <= 0 || policy.Window <= 0 {
+			ctx.Next()
+			return
+		}
+
+		store := policy.Store
+		if store == nil {
+			store = DefaultRateLimitStore
+		}
+
+		key := policy.Name + ":" + policy.Key(ctx)
+		if policy.PerRoute {
+			key += ":" + ctx.Request.Method + " " + ctx.FullPath()
+		}
+
+		result, err := store.Take(key, rule, time.Now())
+		if err != nil {
+			fmt.Println("Rate limit store error:", err)
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+		ctx.Header("RateLimit-Policy", policyHeader)
+
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, please slow down"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
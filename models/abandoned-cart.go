@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// dicatat oleh job abandoned cart saat cart tidak disentuh melewati batas waktu
+type AbandonedCartEvent struct {
+    ID               uint       `gorm:"primaryKey" json:"id"`
+    CartID           uint       `gorm:"index;not null" json:"cart_id"`
+    UserID           uint       `gorm:"index;not null" json:"user_id"`
+    LastActivityAt   time.Time  `json:"last_activity_at"` // updated_at item cart terakhir
+    ItemCount        int        `json:"item_count"`
+    CartTotal        int64      `json:"cart_total"`
+    ReminderSentAt   *time.Time `json:"reminder_sent_at"`
+    ConvertedOrderID *uint      `gorm:"index" json:"converted_order_id"` // order pertama setelah event
+    ConvertedAt      *time.Time `json:"converted_at"`
+    CreatedAt        time.Time  `json:"created_at"`
+    UpdatedAt        time.Time  `json:"updated_at"`
+}
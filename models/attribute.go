@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// tipe data attribute
+const (
+    AttributeText    = "text"
+    AttributeNumber  = "number"
+    AttributeBoolean = "boolean"
+    AttributeEnum    = "enum"
+)
+
+// attribute/spesifikasi produk, contoh: color, ram, size
+type Attribute struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Name      string    `gorm:"size:100;not null" json:"name"`
+    Slug      string    `gorm:"uniqueIndex;size:100;not null" json:"slug"` // dipakai di filter ?attr[slug]=value
+    Type      string    `gorm:"size:20;not null;default:'text'" json:"type"`
+    Unit      string    `gorm:"size:20" json:"unit"`
+    Options   string    `gorm:"type:text" json:"options"` // pilihan untuk tipe enum, dipisah koma
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// attribute set per category
+type CategoryAttribute struct {
+    CategoryID  uint      `gorm:"primaryKey;autoIncrement:false" json:"category_id"`
+    Category    Category  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+    AttributeID uint      `gorm:"primaryKey;autoIncrement:false" json:"attribute_id"`
+    Attribute   Attribute `gorm:"constraint:OnDelete:CASCADE;" json:"attribute"`
+    Required    bool      `gorm:"default:false" json:"required"`
+}
+
+// nilai attribute untuk satu produk
+type AttributeValue struct {
+    ID          uint      `gorm:"primaryKey" json:"id"`
+    ProductID   uint      `gorm:"uniqueIndex:idx_product_attribute;not null" json:"product_id"`
+    AttributeID uint      `gorm:"uniqueIndex:idx_product_attribute;index:idx_attribute_value;not null" json:"attribute_id"`
+    Attribute   Attribute `gorm:"constraint:OnDelete:CASCADE;" json:"attribute"`
+    Value       string    `gorm:"size:255;index:idx_attribute_value;not null" json:"value"`
+    NumberValue *float64  `json:"number_value,omitempty"` // terisi untuk tipe number
+}
+
+type AttributeInput struct {
+    Name    string   `json:"name" binding:"required,min=1,max=100"`
+    Slug    string   `json:"slug" binding:"omitempty,max=100"`
+    Type    string   `json:"type" binding:"required,oneof=text number boolean enum"`
+    Unit    string   `json:"unit" binding:"max=20"`
+    Options string   `json:"options"` // contoh: "S,M,L,XL"
+}
+
+type CategoryAttributeInput struct {
+    Attributes []struct {
+        AttributeID uint `json:"attribute_id" binding:"required"`
+        Required    bool `json:"required"`
+    } `json:"attributes" binding:"dive"`
+}
+
+// key = slug attribute, value = nilai
+type ProductAttributesInput struct {
+    Values map[string]string `json:"values" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Jenis kejadian yang dicatat di audit log
+const (
+    AuditAccountLocked   = "account_locked"
+    AuditAccountUnlocked = "account_unlocked"
+    AuditRolesUpdated    = "roles_updated"
+)
+
+// Catatan kejadian keamanan / aksi admin
+type AuditLog struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Action    string    `gorm:"size:50;index;not null" json:"action"`
+    UserID    *uint     `gorm:"index" json:"user_id"`  // user yang terdampak
+    ActorID   *uint     `json:"actor_id"`              // nil jika dilakukan sistem
+    IPAddress string    `gorm:"size:45" json:"ip_address"`
+    Details   string    `gorm:"type:text" json:"details"`
+    CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -4,12 +4,13 @@ import "time"
 
 // carts (keranjang utama) model
 type Cart struct {
-    ID        uint       `gorm:"primaryKey" json:"id"`
-    UserID    uint       `gorm:"uniqueIndex" json:"user_id"`
-    User      *User       `json:"user"`
-    Items     []CartItem `gorm:"constraint:OnDelete:CASCADE;" json:"items"`
-    CreatedAt time.Time  `json:"created_at"`
-    UpdatedAt time.Time  `json:"updated_at"`
+    ID         uint       `gorm:"primaryKey" json:"id"`
+    UserID     *uint      `gorm:"uniqueIndex" json:"user_id"` // nil untuk guest cart
+    GuestToken *string    `gorm:"uniqueIndex;size:64" json:"-"` // token dari cookie guest_cart
+    User       *User      `json:"user"`
+    Items      []CartItem `gorm:"constraint:OnDelete:CASCADE;" json:"items"`
+    CreatedAt  time.Time  `json:"created_at"`
+    UpdatedAt  time.Time  `json:"updated_at"`
 }
 
 type CartItem struct {
@@ -19,6 +20,10 @@ type CartItem struct {
     ProductID uint    `gorm:"not null" json:"product_id"`
     Product   Product `json:"product"`
     Quantity  int     `gorm:"not null" json:"quantity"`
+    PriceAtAdd int64  `gorm:"not null;default:0" json:"price_at_add"` // harga saat dimasukkan ke cart
+    SavedForLater bool `gorm:"not null;default:false" json:"saved_for_later"` // tidak dihitung di total dan checkout
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `gorm:"index" json:"updated_at"` // aktivitas terakhir, dipakai deteksi abandoned cart
     // Composite unique index: one product per cart
     UniqueKey string `gorm:"-" json:"-"` // not in DB, just placeholder for migration
 }
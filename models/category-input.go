@@ -4,11 +4,14 @@ import ()
 
 // untuk input category
 type CategoryInput struct {
-	Name string `json:"name" binding:"required,min=2,max=100"`
-	Slug string `json:"slug,omitempty" binding:"min=2,max=255"`
+	Name     string `json:"name" binding:"required,min=2,max=100"`
+	Slug     string `json:"slug,omitempty" binding:"omitempty,min=2,max=255"`
+	ParentID *uint  `json:"parent_id,omitempty"`
 }
 
+// ParentID: nil = tidak diubah, 0 = jadikan root category
 type UpdateCategoryInput struct {
-    Name        string `json:"name,omitempty" binding:"min=2,max=255"`
-	Slug		string `json:"slug,omitempty" binding:"min=2,max=255"`
-}
\ No newline at end of file
+    Name        string `json:"name,omitempty" binding:"omitempty,min=2,max=255"`
+	Slug		string `json:"slug,omitempty" binding:"omitempty,min=2,max=255"`
+	ParentID    *uint  `json:"parent_id,omitempty"`
+}
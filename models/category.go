@@ -1,13 +1,28 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // category model
 type Category struct {
     ID        uint      `gorm:"primaryKey" json:"id"`
     Name      string    `gorm:"uniqueIndex;size:100;not null" json:"name"`
     Slug      string    `gorm:"uniqueIndex;size:100;not null" json:"slug"`
+    ParentID  *uint     `gorm:"index" json:"parent_id"`
+    Parent    *Category `gorm:"constraint:OnDelete:SET NULL;" json:"parent,omitempty"`
+    Children  []Category `gorm:"foreignKey:ParentID" json:"children,omitempty"`
     Products  []Product `gorm:"constraint:OnDelete:SET NULL;" json:"products"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
+    DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// satu langkah breadcrumb (root -> leaf)
+type Breadcrumb struct {
+    ID   uint   `json:"id"`
+    Name string `json:"name"`
+    Slug string `json:"slug"`
 }
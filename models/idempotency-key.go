@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Response yang disimpan untuk header Idempotency-Key.
+// StatusCode 0 berarti request pertama masih diproses.
+type IdempotencyKey struct {
+    ID           uint      `gorm:"primaryKey" json:"id"`
+    Scope        string    `gorm:"uniqueIndex:idx_idempotency_scope_key;size:100;not null" json:"scope"` // "user:<id>" / "guest:<token>"
+    Key          string    `gorm:"column:idempotency_key;uniqueIndex:idx_idempotency_scope_key;size:255;not null" json:"key"`
+    Fingerprint  string    `gorm:"size:64;not null" json:"-"` // sha256 dari method, path dan body
+    StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+    ContentType  string    `gorm:"size:255" json:"-"`
+    ResponseBody []byte    `gorm:"type:mediumblob" json:"-"`
+    ExpiresAt    time.Time `gorm:"index" json:"expires_at"`
+    CreatedAt    time.Time `json:"created_at"`
+    UpdatedAt    time.Time `json:"updated_at"`
+}
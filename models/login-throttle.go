@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Penghitung login gagal per akun ("user:<id>" / "login:<username>") dan per IP ("ip:<addr>")
+type LoginThrottle struct {
+    ID            uint       `gorm:"primaryKey" json:"id"`
+    ThrottleKey   string     `gorm:"uniqueIndex;size:191;not null" json:"throttle_key"`
+    Failures      int        `gorm:"not null;default:0" json:"failures"`
+    LastFailureAt time.Time  `json:"last_failure_at"`
+    LockedUntil   *time.Time `json:"locked_until"` // lockout untuk login yang tidak cocok dengan akun mana pun
+}
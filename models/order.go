@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+    "time"
+
+    "gorm.io/gorm"
+)
 
 // model untuk order
 // Enum-like constants
@@ -31,4 +35,23 @@ type OrderItem struct {
     Product   Product `json:"product"`
     Quantity  int     `gorm:"not null" json:"quantity"`
     Price     int64   `gorm:"not null" json:"price"` // per-item price snapshot
-}
\ No newline at end of file
+    // snapshot produk saat order dibuat, tetap sama walau produk diubah/dihapus
+    ProductName string `gorm:"size:255" json:"product_name"`
+    ProductSKU  string `gorm:"column:product_sku;size:64" json:"product_sku"`
+}
+
+// BeforeCreate mengisi snapshot nama/SKU dari produk saat order item dibuat,
+// sehingga setiap jalur pembuatan order menyimpan data produk saat itu
+func (item *OrderItem) BeforeCreate(tx *gorm.DB) error {
+    if item.ProductName != "" || item.ProductID == 0 {
+        return nil
+    }
+    var product Product
+    if err := tx.Session(&gorm.Session{NewDB: true}).Unscoped().
+        Select("name", "sku").First(&product, item.ProductID).Error; err != nil {
+        return err
+    }
+    item.ProductName = product.Name
+    item.ProductSKU = product.SKU
+    return nil
+}
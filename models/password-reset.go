@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Token reset password sekali pakai; hanya hash-nya yang disimpan
+type PasswordResetToken struct {
+    ID        uint       `gorm:"primaryKey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    TokenHash string     `gorm:"uniqueIndex;size:64;not null" json:"-"`
+    ExpiresAt time.Time  `json:"expires_at"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
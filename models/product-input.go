@@ -4,19 +4,36 @@ import ()
 
 // input product 
 type ProductInput struct{
-	Name          string  `json:"name" binding:"required,min=5,max=255"`
-	Description   string  `json:"description" binding:"required,min=10"`
-	Price         int64   `json:"price" binding:"required,gt=0"`
-	StockQuantity int     `json:"stock_quantity" binding:"required"`
-	CategoryID    uint    `json:"category_id"`
-	IsActive      bool    `json:"is_active"`
+	Name            string  `json:"name" binding:"required,min=5,max=255"`
+	SKU             string  `json:"sku" binding:"max=64"`
+	Description     string  `json:"description" binding:"required,min=10"`
+	MetaTitle       string  `json:"meta_title" binding:"max=255"`
+	MetaDescription string  `json:"meta_description" binding:"max=500"`
+	Price           int64   `json:"price" binding:"required,gt=0"`
+	StockQuantity   int     `json:"stock_quantity" binding:"required"`
+	MaxPerOrder     int     `json:"max_per_order" binding:"min=0"`
+	CategoryID      uint    `json:"category_id"`
+	IsActive        bool    `json:"is_active"`
 }
 
+// pointer dipakai supaya nilai 0/false bisa dibedakan dari field yang tidak dikirim
 type UpdateProductInput struct{
-	Name 		  string  `json:"name,omitempty" binding:"min=5,max=255"`
-	Description   string  `json:"description,omitempty" binding:"min=10"`
-	Price 		  int64   `json:"price,omitempty" binding:"gt=0"`
-	StockQuantity int     `json:"stock_quantity,omitempty"`
-	CategoryID    uint    `json:"category_id,omitempty"`
-	IsActive      bool    `json:"is_active,omitempty"`
-}
\ No newline at end of file
+	Name 		    string  `json:"name,omitempty" binding:"omitempty,min=5,max=255"`
+	SKU             *string `json:"sku,omitempty" binding:"omitempty,max=64"`
+	Description     string  `json:"description,omitempty" binding:"omitempty,min=10"`
+	MetaTitle       *string `json:"meta_title,omitempty" binding:"omitempty,max=255"`
+	MetaDescription *string `json:"meta_description,omitempty" binding:"omitempty,max=500"`
+	Price 		    int64   `json:"price,omitempty" binding:"omitempty,gt=0"`
+	StockQuantity   *int    `json:"stock_quantity,omitempty"`
+	MaxPerOrder     *int    `json:"max_per_order,omitempty" binding:"omitempty,min=0"`
+	CategoryID      uint    `json:"category_id,omitempty"`
+	IsActive        *bool   `json:"is_active,omitempty"`
+}
+
+
+// hasil validasi satu baris CSV import
+type ProductImportError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
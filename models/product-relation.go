@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Sumber rekomendasi produk terkait
+const (
+    RelationCoPurchase   = "co_purchase"
+    RelationSameCategory = "same_category"
+)
+
+// hasil precompute produk terkait, diisi ulang oleh job periodik
+type ProductRelation struct {
+    ProductID        uint      `gorm:"primaryKey;autoIncrement:false" json:"product_id"`
+    RelatedProductID uint      `gorm:"primaryKey;autoIncrement:false" json:"related_product_id"`
+    RelatedProduct   Product   `gorm:"foreignKey:RelatedProductID;constraint:OnDelete:CASCADE;" json:"product"`
+    Source           string    `gorm:"size:20;not null" json:"source"`
+    CoPurchaseCount  int       `gorm:"not null;default:0" json:"co_purchase_count"`
+    Score            float64   `gorm:"index;not null;default:0" json:"score"`
+    UpdatedAt        time.Time `json:"updated_at"`
+}
@@ -1,17 +1,40 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 //  product model 
 type Product struct {
-    ID            uint      `gorm:"primaryKey" json:"id"`
-    Name          string    `gorm:"size:200;not null" json:"name"`
-    Description   string    `gorm:"type:text" json:"description"`
-    Price         int64     `gorm:"not null" json:"price"` // store in cents (129900 = Rp1299.00)
-    StockQuantity int       `gorm:"not null;default:0" json:"stock_quantity"`
-    CategoryID    uint      `json:"category_id"`
-    IsActive      bool      `gorm:"default:true" json:"is_active"`
-    Category      Category  `json:"category"`
-    CreatedAt     time.Time `json:"created_at"`
-    UpdatedAt     time.Time `json:"updated_at"`
-}
\ No newline at end of file
+    ID              uint      `gorm:"primaryKey" json:"id"`
+    Name            string    `gorm:"size:200;not null" json:"name"`
+    SKU             string    `gorm:"column:sku;uniqueIndex;size:64;default:null" json:"sku"`
+    Slug            string    `gorm:"uniqueIndex;size:255;default:null" json:"slug"`
+    Description     string    `gorm:"type:text" json:"description"`
+    MetaTitle       string    `gorm:"size:255" json:"meta_title"`
+    MetaDescription string    `gorm:"size:500" json:"meta_description"`
+    Price           int64     `gorm:"not null" json:"price"` // store in cents (129900 = Rp1299.00)
+    StockQuantity   int       `gorm:"not null;default:0" json:"stock_quantity"`
+    MaxPerOrder     int       `gorm:"not null;default:0" json:"max_per_order"` // 0 = tanpa batas
+    CategoryID      uint      `json:"category_id"`
+    IsActive        bool      `gorm:"default:true" json:"is_active"`
+    Category        Category  `json:"category"`
+    Attributes      []AttributeValue `gorm:"constraint:OnDelete:CASCADE;" json:"attributes,omitempty"`
+    RatingCount     int       `gorm:"not null;default:0" json:"rating_count"`
+    RatingSum       int64     `gorm:"not null;default:0" json:"-"`
+    RatingAverage   float64   `gorm:"not null;default:0" json:"rating_average"`
+    CreatedAt       time.Time `json:"created_at"`
+    UpdatedAt       time.Time `json:"updated_at"`
+    DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+}
+
+// slug lama produk (setelah rename), dipakai untuk redirect ke slug terbaru
+type ProductSlugRedirect struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Slug      string    `gorm:"uniqueIndex;size:255;not null" json:"slug"`
+    ProductID uint      `gorm:"index;not null" json:"product_id"`
+    Product   Product   `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+    CreatedAt time.Time `json:"created_at"`
+}
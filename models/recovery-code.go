@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Recovery code 2FA sekali pakai; hanya hash-nya yang disimpan
+type RecoveryCode struct {
+    ID        uint       `gorm:"primaryKey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    CodeHash  string     `gorm:"size:64;not null" json:"-"`
+    UsedAt    *time.Time `json:"used_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Refresh token opaque, hanya hash-nya yang disimpan.
+// Setiap rotasi membuat token baru dalam family yang sama (satu family = satu Session).
+type RefreshToken struct {
+    ID        uint       `gorm:"primaryKey" json:"id"`
+    UserID    uint       `gorm:"index;not null" json:"user_id"`
+    SessionID uint       `gorm:"index" json:"session_id"`
+    FamilyID  string     `gorm:"index;size:64;not null" json:"family_id"`
+    TokenHash string     `gorm:"uniqueIndex;size:64;not null" json:"-"` // sha256 hex
+    ExpiresAt time.Time  `json:"expires_at"`
+    UsedAt    *time.Time `json:"used_at"`    // sudah dirotasi; dipakai lagi berarti reuse
+    RevokedAt *time.Time `json:"revoked_at"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+type RefreshTokenInput struct {
+    RefreshToken string `form:"refresh_token" json:"refresh_token"` // boleh kosong jika dikirim lewat cookie
+}
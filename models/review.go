@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// status moderasi review
+const (
+    ReviewPending  = "pending"
+    ReviewApproved = "approved"
+    ReviewHidden   = "hidden"
+)
+
+// review produk dari pembeli terverifikasi, satu review per user per produk
+type Review struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    ProductID uint      `gorm:"uniqueIndex:idx_review_user_product;not null" json:"product_id"`
+    Product   Product   `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+    UserID    uint      `gorm:"uniqueIndex:idx_review_user_product;not null" json:"user_id"`
+    User      User      `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+    Rating    int       `gorm:"not null" json:"rating"`
+    Title     string    `gorm:"size:150" json:"title"`
+    Body      string    `gorm:"type:text" json:"body"`
+    Status    string    `gorm:"size:20;index;default:'pending'" json:"status"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ReviewInput struct {
+    Rating int    `json:"rating" binding:"required,min=1,max=5"`
+    Title  string `json:"title" binding:"required,min=3,max=150"`
+    Body   string `json:"body" binding:"max=5000"`
+}
+
+type UpdateReviewStatusInput struct {
+    Status string `json:"status" binding:"required,oneof=approved hidden"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Access token (jti) yang dicabut sebelum kedaluwarsa, misalnya karena logout.
+// Baris boleh dihapus setelah ExpiresAt karena token-nya sudah tidak berlaku.
+type RevokedToken struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    JTI       string    `gorm:"column:jti;uniqueIndex;size:64;not null" json:"jti"`
+    UserID    uint      `gorm:"index;not null" json:"user_id"`
+    ExpiresAt time.Time `gorm:"index" json:"expires_at"`
+    CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+// Permission dengan format "<resource>:<aksi>", dicek oleh middlewares.RequirePermission
+const (
+    PermUsersRead        = "users:read"
+    PermUsersUpdate      = "users:update" // termasuk unlock dan cabut token
+    PermUsersDelete      = "users:delete" // termasuk restore
+    PermRolesManage      = "roles:manage"
+    PermProductsRead     = "products:read"
+    PermProductsCreate   = "products:create"
+    PermProductsUpdate   = "products:update"
+    PermProductsDelete   = "products:delete"
+    PermCategoriesRead   = "categories:read"
+    PermCategoriesCreate = "categories:create"
+    PermCategoriesUpdate = "categories:update"
+    PermCategoriesDelete = "categories:delete"
+    PermAttributesManage = "attributes:manage"
+    PermReviewsRead      = "reviews:read"
+    PermReviewsModerate  = "reviews:moderate"
+    PermOrdersRead       = "orders:read"
+    PermOrdersUpdate     = "orders:update"
+)
+
+// Role bawaan yang dibuat saat startup
+const (
+    RoleSuperAdmin      = "super_admin"
+    RoleCatalogManager  = "catalog_manager"
+    RoleOrderFulfilment = "order_fulfilment"
+    RoleSupport         = "support"
+)
+
+// Semua permission yang dikenal aplikasi beserta deskripsinya
+var AllPermissions = map[string]string{
+    PermUsersRead:        "View user accounts",
+    PermUsersUpdate:      "Edit user accounts, unlock them and revoke their sessions",
+    PermUsersDelete:      "Delete and restore user accounts",
+    PermRolesManage:      "Assign roles to users",
+    PermProductsRead:     "View the admin product list, including deleted products, and export it",
+    PermProductsCreate:   "Create and import products",
+    PermProductsUpdate:   "Edit products and their attributes",
+    PermProductsDelete:   "Delete and restore products",
+    PermCategoriesRead:   "View the admin category list, including deleted categories",
+    PermCategoriesCreate: "Create categories",
+    PermCategoriesUpdate: "Edit categories and their attributes",
+    PermCategoriesDelete: "Delete and restore categories",
+    PermAttributesManage: "Create, edit and delete product attributes",
+    PermReviewsRead:      "View all reviews, including pending ones",
+    PermReviewsModerate:  "Approve and reject reviews",
+    PermOrdersRead:       "View orders of all users",
+    PermOrdersUpdate:     "Change order status",
+}
+
+// Permission untuk role bawaan. super_admin selalu mendapat semua permission.
+var DefaultRolePermissions = map[string][]string{
+    RoleCatalogManager: {
+        PermProductsRead, PermProductsCreate, PermProductsUpdate, PermProductsDelete,
+        PermCategoriesRead, PermCategoriesCreate, PermCategoriesUpdate, PermCategoriesDelete,
+        PermAttributesManage,
+    },
+    RoleOrderFulfilment: {PermOrdersRead, PermOrdersUpdate},
+    RoleSupport: {
+        PermUsersRead, PermUsersUpdate, PermOrdersRead, PermReviewsRead, PermReviewsModerate,
+    },
+}
+
+type Permission struct {
+    ID          uint   `gorm:"primaryKey" json:"id"`
+    Name        string `gorm:"uniqueIndex;size:100;not null" json:"name"`
+    Description string `gorm:"size:255" json:"description"`
+}
+
+type Role struct {
+    ID          uint         `gorm:"primaryKey" json:"id"`
+    Name        string       `gorm:"uniqueIndex;size:50;not null" json:"name"`
+    Description string       `gorm:"size:255" json:"description"`
+    Permissions []Permission `gorm:"many2many:role_permissions;constraint:OnDelete:CASCADE;" json:"permissions"`
+    CreatedAt   time.Time    `json:"created_at"`
+    UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// input untuk mengganti role seorang user
+type AssignRolesInput struct {
+    Roles []string `json:"roles"` // nama role; kosong = cabut semua role
+}
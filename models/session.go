@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Satu session per login (per device). Access token membawa session id (sid)
+// dan refresh token terikat ke session, sehingga mencabut session membuat
+// keduanya tidak berlaku.
+type Session struct {
+    ID         uint       `gorm:"primaryKey" json:"id"`
+    UserID     uint       `gorm:"index;not null" json:"user_id"`
+    DeviceName string     `gorm:"size:100" json:"device_name"`
+    UserAgent  string     `gorm:"size:255" json:"user_agent"`
+    IPAddress  string     `gorm:"size:45" json:"ip_address"`
+    LastSeenAt time.Time  `json:"last_seen_at"`
+    TwoFactor  bool       `gorm:"default:false" json:"two_factor"` // login lewat verifikasi 2FA
+    RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+    CreatedAt  time.Time  `json:"created_at"`
+}
@@ -11,6 +11,7 @@ type RegisInput struct {
 type LoginInput struct {
     Login string `form:"login" json:"login" binding:"required,min=3,max=32"`
     Password string `form:"password" json:"password" binding:"required,min=6"`
+    DeviceName string `form:"device_name" json:"device_name" binding:"omitempty,max=100"` // nama device untuk daftar sesi
 }
 
 type LoginInputEmail struct {
@@ -22,6 +23,37 @@ type SetPasswordInput struct {
     NewPassword     string `json:"new_password" binding:"required,min=6"`
 }
 
+type ForgotPasswordInput struct {
+    Email string `form:"email" json:"email" binding:"required,email"`
+}
+
+type ResetPasswordInput struct {
+    Token       string `form:"token" json:"token" binding:"required"`
+    NewPassword string `form:"new_password" json:"new_password" binding:"required,min=6"`
+}
+
+type VerifyEmailInput struct {
+    Token string `form:"token" json:"token" binding:"required"`
+}
+
+type TwoFactorCodeInput struct {
+    Code string `form:"code" json:"code" binding:"required"`
+}
+
+// Langkah kedua login: kode TOTP atau salah satu recovery code
+type TwoFactorLoginInput struct {
+    ChallengeToken string `form:"challenge_token" json:"challenge_token"` // boleh kosong jika dikirim lewat cookie
+    Code           string `form:"code" json:"code"`
+    RecoveryCode   string `form:"recovery_code" json:"recovery_code"`
+    DeviceName     string `form:"device_name" json:"device_name" binding:"omitempty,max=100"`
+}
+
+type DisableTwoFactorInput struct {
+    Password     string `json:"password"`
+    Code         string `json:"code"`
+    RecoveryCode string `json:"recovery_code"`
+}
+
 type UpdatePasswordInput struct {
     OldPassword     string `json:"old_password" binding:"required,min=6"`
     NewPassword     string `json:"new_password" binding:"required,min=6"`
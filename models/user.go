@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User model
 type User struct {
@@ -8,9 +12,22 @@ type User struct {
 	Username string `gorm:"uniqueIndex;size:100;not null" json:"username"`
 	Email    string `gorm:"uniqueIndex;size:100;not null" json:"email"`
 	Password string `json:"-"`
-	Admin    bool   `gorm:"default:false" json:"admin"`
 	Provider string `gorm:"size:50;default:local" json:"provider"`
 
+	EmailVerifiedAt *time.Time `json:"email_verified_at"` // nil sampai link verifikasi dibuka
+
+	// 2FA (TOTP): secret diisi saat setup, aktif setelah TwoFactorEnabledAt diisi
+	TOTPSecret         string     `gorm:"column:totp_secret;size:64" json:"-"`
+	TOTPLastStep       int64      `gorm:"column:totp_last_step;default:0" json:"-"` // mencegah kode yang sama dipakai dua kali
+	TwoFactorEnabledAt *time.Time `json:"two_factor_enabled_at"`
+
+	LockedUntil *time.Time `json:"locked_until,omitempty"` // dikunci sementara setelah terlalu banyak login gagal
+
+	// token yang diterbitkan sebelum waktu ini ditolak ("log out all sessions")
+	TokensValidAfter *time.Time `json:"-"`
+
+	Roles []Role `gorm:"many2many:user_roles;constraint:OnDelete:CASCADE;" json:"roles,omitempty"`
+
 	Addresses []Address `gorm:"constraint:OnDelete:CASCADE;" json:"addresses"`
 
 	Orders []Order `json:"orders"`
@@ -18,6 +35,7 @@ type User struct {
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
 //struct input untuk controller
@@ -29,8 +47,8 @@ type UpdateProfileInput struct {
 }
 
 // UpdateUserAdminInput digunakan untuk menerima data saat Admin mengupdate user lain
+// Hak akses diatur lewat role, lihat AssignRolesInput
 type UpdateUserAdminInput struct {
 	Username string `json:"username,omitempty"`
 	Email    string `json:"email,omitempty"`
-	Admin    *bool  `json:"admin,omitempty"`
 }
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// wishlist (produk yang disimpan user untuk nanti)
+type Wishlist struct {
+    ID        uint           `gorm:"primaryKey" json:"id"`
+    UserID    uint           `gorm:"uniqueIndex" json:"user_id"`
+    Items     []WishlistItem `gorm:"constraint:OnDelete:CASCADE;" json:"items"`
+    CreatedAt time.Time      `json:"created_at"`
+    UpdatedAt time.Time      `json:"updated_at"`
+}
+
+type WishlistItem struct {
+    ID         uint      `gorm:"primaryKey" json:"id"`
+    WishlistID uint      `gorm:"uniqueIndex:idx_wishlist_product;not null" json:"wishlist_id"`
+    ProductID  uint      `gorm:"uniqueIndex:idx_wishlist_product;not null" json:"product_id"`
+    Product    Product   `gorm:"constraint:OnDelete:CASCADE;" json:"product"`
+    PriceAtAdd int64     `gorm:"not null" json:"price_at_add"` // harga saat disimpan
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+type AddWishlistItemInput struct {
+	ProductID uint `json:"product_id" binding:"required,gt=0"`
+}
+
+type MoveWishlistItemToCartInput struct {
+	Quantity uint `json:"quantity" binding:"omitempty,gt=0"`
+}
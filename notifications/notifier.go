@@ -0,0 +1,96 @@
+package notifications
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Jenis notifikasi yang dikirim ke user
+const (
+	TypePriceDrop     = "price_drop"
+	TypeBackInStock   = "back_in_stock"
+	TypeAbandonedCart = "abandoned_cart"
+)
+
+type Notification struct {
+	UserID  uint                   `json:"user_id"`
+	Email   string                 `json:"email"`
+	Type    string                 `json:"type"`
+	Subject string                 `json:"subject"`
+	Message string                 `json:"message"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	SentAt  time.Time              `json:"sent_at"`
+}
+
+// Notifier mengirim notifikasi ke user (email, push, dll)
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// LogNotifier hanya menulis notifikasi ke log, dipakai untuk development
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(n Notification) error {
+	if n.SentAt.IsZero() {
+		n.SentAt = time.Now()
+	}
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	log.Println("[notification]", string(payload))
+	return nil
+}
+
+// FileNotifier menambahkan notifikasi sebagai satu baris JSON ke file,
+// berguna untuk development dan test yang perlu membaca notifikasi terkirim
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (f *FileNotifier) Notify(n Notification) error {
+	if n.SentAt.IsZero() {
+		n.SentAt = time.Now()
+	}
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(payload, '\n'))
+	return err
+}
+
+// Default notifier yang dipakai aplikasi, bisa diganti saat startup
+var Default Notifier = LogNotifier{}
+
+// Setup memilih Default notifier dari config ("log" atau "file")
+func Setup(kind, path string) {
+	switch kind {
+	case "file":
+		Default = &FileNotifier{Path: path}
+	case "", "log":
+		Default = LogNotifier{}
+	default:
+		log.Printf("Unknown notifier %q, using log\n", kind)
+		Default = LogNotifier{}
+	}
+}
+
+// Send mengirim lewat Default notifier dan mencatat error tanpa menghentikan proses
+func Send(n Notification) {
+	if err := Default.Notify(n); err != nil {
+		log.Println("Failed to send notification:", err)
+	}
+}
@@ -3,12 +3,34 @@ package routes
 import (
 	"net/http"
 
+	"github.com/ASaifaji/as-gin-ecommerce/config"
 	"github.com/ASaifaji/as-gin-ecommerce/controllers"
 	"github.com/ASaifaji/as-gin-ecommerce/middlewares"
+	"github.com/ASaifaji/as-gin-ecommerce/models"
 	"github.com/gin-gonic/gin"
 )
 
 func SetupRoutes(r *gin.Engine) {
+	// Rate limit untuk endpoint yang rawan brute force / scraping
+	authLimit := middlewares.RateLimit(middlewares.RateLimitPolicy{
+		Name:      "auth",
+		Algorithm: middlewares.SlidingWindow,
+		Limit:     config.RateLimitConfig.AuthLimit,
+		Window:    config.RateLimitConfig.AuthWindow,
+		Key:       middlewares.RateLimitByIP,
+		PerRoute:  true,
+	})
+	searchLimit := middlewares.RateLimit(middlewares.RateLimitPolicy{
+		Name:      "search",
+		Algorithm: middlewares.TokenBucket,
+		Limit:     config.RateLimitConfig.SearchLimit,
+		Window:    config.RateLimitConfig.SearchWindow,
+		Key:       middlewares.RateLimitByIP,
+	})
+
+	// Public key JWT untuk service lain
+	r.GET("/.well-known/jwks.json", controllers.GetJWKS)
+
 	api := r.Group("/api")
 	{
 		// Check Health
@@ -18,44 +40,106 @@ func SetupRoutes(r *gin.Engine) {
 		api.GET("/authToken", middlewares.AuthMiddleware())
 
 		// Users
-		api.POST("/register", func(ctx *gin.Context) { controllers.Register(ctx) })
-		api.POST("/login", func(ctx *gin.Context) { controllers.Login(ctx) })
+		auth := api.Group("", authLimit)
+		{
+			auth.POST("/register", func(ctx *gin.Context) { controllers.Register(ctx) })
+			auth.POST("/login", func(ctx *gin.Context) { controllers.Login(ctx) })
+			auth.POST("/auth/password/forgot", controllers.ForgotPassword)
+			auth.POST("/auth/password/reset", controllers.ResetPassword)
+			auth.POST("/auth/verify-email", controllers.VerifyEmail)
+			auth.POST("/auth/2fa/verify", controllers.VerifyTwoFactorLogin)
+		}
 		api.POST("/logout", middlewares.Logout)
+		api.POST("/auth/logout", middlewares.Logout) // path cookie refresh_token, supaya session ikut dicabut walau access token sudah kedaluwarsa
+		api.POST("/logout/all", middlewares.AuthMiddleware(), middlewares.LogoutAllSessions)
+		api.POST("/auth/refresh", controllers.RefreshAuthToken)
+		api.POST("/profile/2fa/setup", middlewares.AuthMiddleware(), controllers.SetupTwoFactor)
+		api.POST("/profile/2fa/enable", middlewares.AuthMiddleware(), controllers.EnableTwoFactor)
+		api.POST("/profile/2fa/disable", middlewares.AuthMiddleware(), controllers.DisableTwoFactor)
+		api.POST("/profile/2fa/recovery-codes", middlewares.AuthMiddleware(), controllers.RegenerateRecoveryCodes)
+		api.POST("/auth/verify-email/resend", middlewares.AuthMiddleware(), controllers.ResendVerificationEmail)
 		api.GET("/profile", middlewares.AuthMiddleware(), controllers.GetProfile)
+		api.GET("/profile/sessions", middlewares.AuthMiddleware(), controllers.GetOwnSessions)
+		api.DELETE("/profile/sessions/:id", middlewares.AuthMiddleware(), controllers.RevokeOwnSession)
 		api.PUT("/profile", middlewares.AuthMiddleware(), controllers.UpdateProfile)
 		api.PUT("/profile/password", middlewares.AuthMiddleware(), controllers.UpdatePassword)
-		api.GET("/users", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.GetAllUsers)
+		api.GET("/users", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersRead), controllers.GetAllUsers)
 		api.GET("/users/:id", middlewares.AuthMiddleware(), controllers.GetUserDetail)
-		api.PUT("/users/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.UpdateUser)
-		api.DELETE("/users/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.DeleteUser)
+		api.PUT("/users/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersUpdate), controllers.UpdateUser)
+		api.DELETE("/users/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersDelete), controllers.DeleteUser)
+		api.POST("/admin/users/:id/restore", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersDelete), controllers.RestoreUser)
+		api.POST("/admin/users/:id/revoke-tokens", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersUpdate), controllers.RevokeUserTokens)
+		api.POST("/admin/users/:id/unlock", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermUsersUpdate), controllers.UnlockUser)
+
+		// Role & permission
+		api.GET("/admin/roles", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermRolesManage), controllers.GetAllRoles)
+		api.GET("/admin/permissions", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermRolesManage), controllers.GetAllPermissions)
+		api.GET("/admin/users/:id/roles", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermRolesManage), controllers.GetUserRoles)
+		api.PUT("/admin/users/:id/roles", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermRolesManage), controllers.SetUserRoles)
 
 		// Product
-		api.GET("/products", controllers.GetAllProducts)
+		api.GET("/products", searchLimit, controllers.GetAllProducts)
 		api.GET("/products/:id", controllers.GetProductDetail)
-		api.POST("/products", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.CreateProduct)
-		api.PUT("/products/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.UpdateProduct)
-		api.DELETE("/products/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.DeleteProduct)
+		api.GET("/products/slug/:slug", controllers.GetProductBySlug)
+		api.GET("/products/:id/related", controllers.GetRelatedProducts)
+		api.POST("/products", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsCreate), controllers.CreateProduct)
+		api.PUT("/products/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsUpdate), controllers.UpdateProduct)
+		api.DELETE("/products/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsDelete), controllers.DeleteProduct)
+		api.GET("/admin/products", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsRead), controllers.GetAllProducts)
+		api.POST("/admin/products/:id/restore", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsDelete), controllers.RestoreProduct)
+		api.POST("/admin/products/import", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsCreate), controllers.ImportProducts)
+		api.GET("/admin/products/export.csv", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsRead), controllers.ExportProducts)
+		api.PUT("/admin/products/:id/attributes", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermProductsUpdate), controllers.SetProductAttributes)
+
+		// Attribute
+		api.GET("/attributes", controllers.GetAllAttributes)
+		api.POST("/admin/attributes", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermAttributesManage), controllers.CreateAttribute)
+		api.PUT("/admin/attributes/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermAttributesManage), controllers.UpdateAttribute)
+		api.DELETE("/admin/attributes/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermAttributesManage), controllers.DeleteAttribute)
+		api.GET("/categories/:id/attributes", controllers.GetCategoryAttributes)
+		api.PUT("/admin/categories/:id/attributes", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesUpdate), controllers.SetCategoryAttributes)
+
+		// Review
+		api.GET("/products/:id/reviews", controllers.GetProductReviews)
+		api.POST("/products/:id/reviews", middlewares.AuthMiddleware(), controllers.CreateReview)
+		api.GET("/admin/reviews", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermReviewsRead), controllers.GetAllReviews)
+		api.PUT("/admin/reviews/:id/status", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermReviewsModerate), controllers.UpdateReviewStatus)
 
 		// Order
-		api.POST("/orders", middlewares.AuthMiddleware(), controllers.CreateOrder)
+		api.POST("/orders", middlewares.AuthMiddleware(), middlewares.Idempotency(), controllers.CreateOrder)
 		api.GET("/orders", middlewares.AuthMiddleware(), controllers.GetAllOwnOrders)
 		api.GET("/orders/:id", middlewares.AuthMiddleware(), controllers.GetOrderDetail)
-		api.GET("/admin/orders", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.GetAllOrders)
-		api.PUT("/orders/:id/status", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.UpdateOrderStatus)
+		api.POST("/orders/:id/reorder", middlewares.AuthMiddleware(), middlewares.Idempotency(), controllers.ReorderOrder)
+		api.GET("/admin/orders", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermOrdersRead), controllers.GetAllOrders)
+		api.PUT("/orders/:id/status", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermOrdersUpdate), controllers.UpdateOrderStatus)
 
 		// Category
 		api.GET("/categories", controllers.GetAllCategories)
+		api.GET("/categories/tree", controllers.GetCategoryTree)
 		api.GET("/categories/:id", controllers.GetCategories)
-		api.POST("/categories", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.CreateCategory)
-		api.PUT("/categories/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.UpdateCategories)
-		api.DELETE("/categories/:id", middlewares.AuthMiddleware(), middlewares.AuthAdmin(), controllers.DeleteCategory)
+		api.POST("/categories", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesCreate), controllers.CreateCategory)
+		api.PUT("/categories/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesUpdate), controllers.UpdateCategories)
+		api.DELETE("/categories/:id", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesDelete), controllers.DeleteCategory)
+		api.GET("/admin/categories", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesRead), controllers.GetAllCategories)
+		api.POST("/admin/categories/:id/restore", middlewares.AuthMiddleware(), middlewares.RequirePermission(models.PermCategoriesDelete), controllers.RestoreCategory)
+
+		// Cart (user login atau guest lewat cookie guest_cart)
+		cart := api.Group("/cart", middlewares.OptionalAuth(), middlewares.GuestCart())
+		{
+			cart.GET("", controllers.GetOwnCart)
+			cart.POST("", controllers.AddProductToCart)
+			cart.PUT("/:itemId", controllers.UpdateCartItem)
+			cart.DELETE("/:itemId", controllers.RemoveCartItem)
+			cart.DELETE("/clear", controllers.ClearCart)
+			cart.POST("/:itemId/save-for-later", controllers.SaveCartItemForLater)
+			cart.POST("/:itemId/move-to-cart", controllers.MoveSavedItemToCart)
+		}
 
-		// Cart
-		api.GET("/cart", middlewares.AuthMiddleware(), controllers.GetOwnCart)
-		api.POST("/cart", middlewares.AuthMiddleware(), controllers.AddProductToCart)
-		api.PUT("/cart/:itemId", middlewares.AuthMiddleware(), controllers.UpdateCartItem)
-		api.DELETE("/cart/:itemId", middlewares.AuthMiddleware(), controllers.RemoveCartItem)
-		api.DELETE("/cart/clear", middlewares.AuthMiddleware(), controllers.ClearCart)
+		// Wishlist
+		api.GET("/wishlist", middlewares.AuthMiddleware(), controllers.GetOwnWishlist)
+		api.POST("/wishlist", middlewares.AuthMiddleware(), controllers.AddProductToWishlist)
+		api.DELETE("/wishlist/:itemId", middlewares.AuthMiddleware(), controllers.RemoveWishlistItem)
+		api.POST("/wishlist/:itemId/move-to-cart", middlewares.AuthMiddleware(), controllers.MoveWishlistItemToCart)
 
 		// google OAuth2
 		api.GET("auth/google/login", controllers.GoogleLogin)
@@ -89,6 +173,7 @@ func SetupRoutes(r *gin.Engine) {
 		vLogin := view.Group("/login")
 		{
 			vLogin.GET("", controllers.ViewLogin)
+			vLogin.GET("/2fa", controllers.ViewTwoFactorLogin)
 		}
 	}
 }
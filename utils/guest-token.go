@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// GuestCartCookie is the cookie holding the signed guest cart token
+const GuestCartCookie = "guest_cart"
+
+// NewGuestToken generates a random identifier for an anonymous (guest) cart
+func NewGuestToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SignGuestToken returns "token.signature" for storing in a cookie
+func SignGuestToken(token string, secret []byte) string {
+	return SignValue(token, secret)
+}
+
+// VerifyGuestToken checks the cookie signature and returns the raw token
+func VerifyGuestToken(value string, secret []byte) (string, bool) {
+	return VerifySignedValue(value, secret)
+}
@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKeyOptions describes the active signing key and the extra keys that are
+// still accepted for verification (keys being rotated out).
+type JWTKeyOptions struct {
+	Algorithm      string // HS256, RS256 atau EdDSA
+	KeyID          string // kid untuk token baru
+	Secret         string // HS256
+	PrivateKeyFile string // PEM untuk RS256 / EdDSA
+	// "kid:ALG:value" dipisah koma; value berupa secret untuk HS256 atau
+	// path file PEM (public atau private key) untuk RS256 / EdDSA
+	VerificationKeys string
+}
+
+// Panjang minimum secret HMAC (256 bit untuk HS256)
+const MinHMACSecretLength = 32
+
+type jwtKey struct {
+	ID     string
+	Method jwt.SigningMethod
+	Sign   interface{} // nil untuk key yang hanya dipakai verifikasi
+	Verify interface{}
+}
+
+var (
+	signingKey      *jwtKey
+	verificationKey = map[string]*jwtKey{}
+)
+
+// LoadJWTKeys replaces the signing and verification keys. Tokens are signed
+// with the active key; any loaded key is accepted by ValidateJWT based on
+// the token's kid header.
+func LoadJWTKeys(opts JWTKeyOptions) error {
+	if opts.KeyID == "" {
+		return errors.New("jwt: key id is required")
+	}
+
+	active, err := loadSigningKey(opts)
+	if err != nil {
+		return err
+	}
+	keys := map[string]*jwtKey{active.ID: active}
+
+	for _, entry := range strings.Split(opts.VerificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("jwt: invalid verification key %q, expected kid:ALG:value", entry)
+		}
+		key, err := loadVerificationKey(parts[0], parts[1], parts[2])
+		if err != nil {
+			return err
+		}
+		if _, dup := keys[key.ID]; dup {
+			return fmt.Errorf("jwt: duplicate key id %q", key.ID)
+		}
+		keys[key.ID] = key
+	}
+
+	signingKey = active
+	verificationKey = keys
+	return nil
+}
+
+func loadSigningKey(opts JWTKeyOptions) (*jwtKey, error) {
+	key := &jwtKey{ID: opts.KeyID}
+
+	switch opts.Algorithm {
+	case "", "HS256":
+		if len(opts.Secret) < MinHMACSecretLength {
+			return nil, fmt.Errorf("jwt: HS256 requires JWT_SECRET of at least %d bytes", MinHMACSecretLength)
+		}
+		key.Method = jwt.SigningMethodHS256
+		key.Sign = []byte(opts.Secret)
+		key.Verify = key.Sign
+	case "RS256", "EdDSA":
+		pemBytes, err := os.ReadFile(opts.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to read private key: %v", err)
+		}
+		private, public, err := parsePrivateKey(opts.Algorithm, pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		key.Method = jwt.GetSigningMethod(opts.Algorithm)
+		key.Sign = private
+		key.Verify = public
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q", opts.Algorithm)
+	}
+
+	return key, nil
+}
+
+func loadVerificationKey(kid, alg, value string) (*jwtKey, error) {
+	key := &jwtKey{ID: kid, Method: jwt.GetSigningMethod(alg)}
+
+	switch alg {
+	case "HS256":
+		if len(value) < MinHMACSecretLength {
+			return nil, fmt.Errorf("jwt: HS256 key %q must be at least %d bytes", kid, MinHMACSecretLength)
+		}
+		key.Verify = []byte(value)
+	case "RS256", "EdDSA":
+		pemBytes, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("jwt: failed to read key %q: %v", kid, err)
+		}
+		public, err := parsePublicKey(alg, pemBytes)
+		if err != nil {
+			// file berisi private key, ambil public key-nya
+			_, derived, privErr := parsePrivateKey(alg, pemBytes)
+			if privErr != nil {
+				return nil, fmt.Errorf("jwt: invalid key %q: %v", kid, err)
+			}
+			public = derived
+		}
+		key.Verify = public
+	default:
+		return nil, fmt.Errorf("jwt: unsupported algorithm %q for key %q", alg, kid)
+	}
+
+	return key, nil
+}
+
+func parsePrivateKey(alg string, pemBytes []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	if alg == "RS256" {
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return private, &private.PublicKey, nil
+	}
+
+	private, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	edPrivate, ok := private.(ed25519.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("jwt: not an Ed25519 private key")
+	}
+	return edPrivate, edPrivate.Public(), nil
+}
+
+func parsePublicKey(alg string, pemBytes []byte) (crypto.PublicKey, error) {
+	if alg == "RS256" {
+		return jwt.ParseRSAPublicKeyFromPEM(pemBytes)
+	}
+	return jwt.ParseEdPublicKeyFromPEM(pemBytes)
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// PublicJWKS returns the public keys of all loaded asymmetric keys.
+// HS256 secrets are never published.
+func PublicJWKS() []JWK {
+	keys := []JWK{}
+	for _, key := range verificationKey {
+		switch public := key.Verify.(type) {
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(public),
+			})
+		}
+	}
+	return keys
+}
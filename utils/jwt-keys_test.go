@@ -0,0 +1,23 @@
+package utils
+
+import "testing"
+
+func TestLoadJWTKeysRejectsShortHMACKeys(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+
+	tests := map[string]JWTKeyOptions{
+		"short signing secret":      {KeyID: "current", Secret: "short"},
+		"empty verification key":    {KeyID: "current", Secret: secret, VerificationKeys: "old:HS256:"},
+		"short verification key":    {KeyID: "current", Secret: secret, VerificationKeys: "old:HS256:short"},
+		"one short key in the list": {KeyID: "current", Secret: secret, VerificationKeys: "old:HS256:" + secret + ",older:HS256:short"},
+	}
+	for name, opts := range tests {
+		if err := LoadJWTKeys(opts); err == nil {
+			t.Errorf("%s: LoadJWTKeys accepted the key", name)
+		}
+	}
+
+	if err := LoadJWTKeys(JWTKeyOptions{KeyID: "current", Secret: secret, VerificationKeys: "old:HS256:" + secret + "-old"}); err != nil {
+		t.Fatalf("valid keys rejected: %v", err)
+	}
+}
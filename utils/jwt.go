@@ -1,42 +1,111 @@
 package utils
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var jwtKey = []byte("supersecretkey")
+// Cookie tempat access token dan refresh token disimpan
+const (
+	AccessTokenCookie      = "auth_token"
+	RefreshTokenCookie     = "refresh_token"
+	RefreshTokenCookiePath = "/api/auth"
+	// challenge 2FA untuk login lewat Google (redirect, jadi tidak bisa lewat body)
+	TwoFactorChallengeCookie = "two_factor_challenge"
+)
+
+// Umur access token, diatur dari config saat startup
+var AccessTokenTTL = 15 * time.Minute
+
+// Token dengan Purpose bukan access token dan ditolak oleh ValidateJWT
+const PurposeTwoFactorChallenge = "2fa_challenge"
 
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Email    string `json:"email"`
-	Admin    bool 	`json:"admin"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID uint   `json:"sid,omitempty"` // models.Session yang menerbitkan token
+	TwoFactor bool   `json:"tfa,omitempty"` // login sudah melewati verifikasi 2FA
+	Purpose   string `json:"purpose,omitempty"`
+	// permission dari role user saat token diterbitkan, lihat middlewares.RequirePermission
+	Permissions []string `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateJWT(userID uint, email string, admin bool) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// GenerateJWT signs an access token for the identity fields of claims
+func GenerateJWT(claims Claims) (string, error) {
+	claims.Purpose = ""
+	return signJWT(claims, AccessTokenTTL)
+}
+
+// GenerateChallengeJWT signs the short-lived token returned by Login when
+// the user still has to pass the second factor
+func GenerateChallengeJWT(userID uint, ttl time.Duration) (string, error) {
+	return signJWT(Claims{UserID: userID, Purpose: PurposeTwoFactorChallenge}, ttl)
+}
+
+func signJWT(claims Claims, ttl time.Duration) (string, error) {
+	// jti dipakai untuk mencabut token sebelum kedaluwarsa (logout)
+	jti, err := NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
 
-	claims := &Claims{
-		UserID: userID,
-		Email:  email,
-		Admin:  admin,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ID:        jti,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtKey)
+	if signingKey == nil {
+		return "", fmt.Errorf("jwt: signing key not loaded")
+	}
+	token := jwt.NewWithClaims(signingKey.Method, &claims)
+	token.Header["kid"] = signingKey.ID
+	return token.SignedString(signingKey.Sign)
 }
 
+// ValidateJWT parses an access token
 func ValidateJWT(tokenStr string) (*Claims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+// ValidateChallengeJWT parses a token created by GenerateChallengeJWT
+func ValidateChallengeJWT(tokenStr string) (*Claims, error) {
+	claims, err := parseJWT(tokenStr)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != PurposeTwoFactorChallenge {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+	return claims, nil
+}
+
+func parseJWT(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 
 	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtKey, nil
+		// Token lama tanpa kid diverifikasi dengan key aktif
+		key := signingKey
+		if kid, ok := token.Header["kid"].(string); ok {
+			key = verificationKey[kid]
+		}
+		if key == nil {
+			return nil, fmt.Errorf("jwt: unknown key id")
+		}
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("jwt: unexpected signing method %s", token.Method.Alg())
+		}
+		return key.Verify, nil
 	})
 	if err != nil {
 		return nil, err
@@ -47,4 +116,4 @@ func ValidateJWT(tokenStr string) (*Claims, error) {
 	}
 
 	return claims, nil
-}
\ No newline at end of file
+}
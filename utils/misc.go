@@ -1,5 +1,11 @@
 package utils
 
+import "time"
+
 func BoolPtr(b bool) *bool {
 	return &b
+}
+
+func TimePtr(t time.Time) *time.Time {
+	return &t
 }
\ No newline at end of file
@@ -1,6 +1,10 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+    "sync"
+
+    "golang.org/x/crypto/bcrypt"
+)
 
 // HashPassword hashes a plain password using bcrypt
 func HashPassword(password string) (string, error) {
@@ -12,4 +16,18 @@ func HashPassword(password string) (string, error) {
 func CheckPassword(hashedPassword, plainPassword string) bool {
     err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(plainPassword))
     return err == nil
-}
\ No newline at end of file
+}
+
+var (
+    dummyHash     []byte
+    dummyHashOnce sync.Once
+)
+
+// CheckDummyPassword spends the same time as CheckPassword for logins that
+// match no account, so response times don't reveal which usernames exist
+func CheckDummyPassword(plainPassword string) {
+    dummyHashOnce.Do(func() {
+        dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+    })
+    bcrypt.CompareHashAndPassword(dummyHash, []byte(plainPassword))
+}
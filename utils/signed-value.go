@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignValue returns "value.signature" where signature is a hex HMAC-SHA256
+// of value. value must not contain a dot.
+func SignValue(value string, secret []byte) string {
+	return value + "." + hmacSignature(value, secret)
+}
+
+// VerifySignedValue checks a value produced by SignValue and returns the
+// original value
+func VerifySignedValue(signed string, secret []byte) (string, bool) {
+	value, signature, found := strings.Cut(signed, ".")
+	if !found || value == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(signature), []byte(hmacSignature(value, secret))) {
+		return "", false
+	}
+	return value, true
+}
+
+func hmacSignature(value string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestSignedValueRoundTrip(t *testing.T) {
+	signed := SignValue("guest123", testSecret)
+	value, ok := VerifySignedValue(signed, testSecret)
+	if !ok || value != "guest123" {
+		t.Fatalf("got (%q, %v), want (\"guest123\", true)", value, ok)
+	}
+}
+
+func TestVerifySignedValueRejectsTampering(t *testing.T) {
+	signed := SignValue("guest123", testSecret)
+	signature := signed[strings.Index(signed, ".")+1:]
+
+	tests := map[string]string{
+		"changed value": "guest124." + signature,
+		"no signature":  "guest123",
+		"empty value":   "." + signature,
+		"wrong secret":  SignValue("guest123", []byte("another-secret-another-secret-xx")),
+		"bad signature": "guest123.deadbeef",
+	}
+	for name, input := range tests {
+		if _, ok := VerifySignedValue(input, testSecret); ok {
+			t.Errorf("%s: %q was accepted", name, input)
+		}
+	}
+}
+
+func TestEmailVerificationToken(t *testing.T) {
+	token := SignEmailVerification(42, "a:b@example.com", time.Now().Add(time.Hour), testSecret)
+	userID, email, ok := VerifyEmailVerification(token, testSecret)
+	if !ok || userID != 42 || email != "a:b@example.com" {
+		t.Fatalf("got (%d, %q, %v), want (42, \"a:b@example.com\", true)", userID, email, ok)
+	}
+
+	expired := SignEmailVerification(42, "a@example.com", time.Now().Add(-time.Minute), testSecret)
+	if _, _, ok := VerifyEmailVerification(expired, testSecret); ok {
+		t.Fatal("expired token was accepted")
+	}
+	if _, _, ok := VerifyEmailVerification(token, []byte("another-secret-another-secret-xx")); ok {
+		t.Fatal("token signed with another secret was accepted")
+	}
+}
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Huruf yang tidak bisa di-decompose oleh NFD (ß, æ, ø, ...)
+var slugTransliterations = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'Æ': "ae", 'ø': "o", 'Ø': "o",
+	'œ': "oe", 'Œ': "oe", 'đ': "d", 'Đ': "d", 'ł': "l",
+	'Ł': "l", 'þ': "th", 'Þ': "th", 'ð': "d", 'Ð': "d",
+}
+
+// Slugify converts text into a lowercase ASCII slug, e.g. "Crème Brûlée" -> "creme-brulee"
+func Slugify(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	text = strings.ReplaceAll(text, "&", " and ")
+	normalized, _, err := transform.String(t, text)
+	if err != nil {
+		normalized = text
+	}
+
+	var b strings.Builder
+	lastDash := true
+	for _, r := range strings.ToLower(normalized) {
+		if repl, ok := slugTransliterations[r]; ok {
+			b.WriteString(repl)
+			lastDash = false
+			continue
+		}
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+		if !lastDash {
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+
+	return strings.Trim(b.String(), "-")
+}
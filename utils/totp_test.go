@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// Secret test RFC 6238 untuk SHA1: ASCII "12345678901234567890"
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Vektor RFC 6238 Appendix B (SHA1), dipotong ke 6 digit terakhir
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	key := []byte("12345678901234567890")
+	for _, v := range rfc6238Vectors {
+		if got := totpCode(key, v.unix/totpPeriod); got != v.code {
+			t.Errorf("T=%d: got %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238Vectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		now := time.Unix(v.unix, 0)
+		step, ok := ValidateTOTP(rfc6238Secret, v.code, now)
+		if !ok {
+			t.Errorf("T=%d: code %s rejected", v.unix, v.code)
+			continue
+		}
+		if want := v.unix / totpPeriod; step != want {
+			t.Errorf("T=%d: got step %d, want %d", v.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	const code = "005924" // berlaku untuk T=1234567890
+	codeStep := int64(1234567890 / totpPeriod)
+	at := func(steps int64) time.Time { return time.Unix((codeStep+steps)*totpPeriod, 0) }
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		now    time.Time
+		ok     bool
+	}{
+		{"same step", rfc6238Secret, code, at(0), true},
+		{"one step early", rfc6238Secret, code, at(-1), true},
+		{"one step late", rfc6238Secret, code, at(1), true},
+		{"two steps early", rfc6238Secret, code, at(-2), false},
+		{"two steps late", rfc6238Secret, code, at(2), false},
+		{"spaces in code", rfc6238Secret, " 005 924 ", at(0), true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", code, at(0), true},
+		{"wrong code", rfc6238Secret, "005925", at(0), false},
+		{"too short", rfc6238Secret, "05924", at(0), false},
+		{"too long", rfc6238Secret, "0059240", at(0), false},
+		{"invalid secret", "not base32!", code, at(0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, tt.now)
+			if ok != tt.ok {
+				t.Fatalf("got ok=%v, want %v", ok, tt.ok)
+			}
+			if ok && step != codeStep {
+				t.Fatalf("got step %d, want %d", step, codeStep)
+			}
+		})
+	}
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	tests := map[string]string{
+		"k3f9-2mqa-x7pd":   "k3f92mqax7pd",
+		" K3F9-2MQA-X7PD ": "k3f92mqax7pd",
+		"k3f9 2mqa x7pd":   "k3f92mqax7pd",
+		"k3f92mqax7pd":     "k3f92mqax7pd",
+	}
+	for in, want := range tests {
+		if got := NormalizeRecoveryCode(in); got != want {
+			t.Errorf("NormalizeRecoveryCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewRecoveryCodeFormat(t *testing.T) {
+	code, err := NewRecoveryCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(code) != 14 || code[4] != '-' || code[9] != '-' {
+		t.Fatalf("unexpected recovery code format %q", code)
+	}
+	if NormalizeRecoveryCode(code) != code[0:4]+code[5:9]+code[10:14] {
+		t.Fatalf("recovery code %q does not round-trip through NormalizeRecoveryCode", code)
+	}
+}